@@ -2,45 +2,27 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"log"
 	"os"
-	"regexp"
-	"slices"
-	"strconv"
 )
 
 const (
 	puzzleInput = "input.txt"
 )
 
-var (
-	digitOnlyRegex = regexp.MustCompile(`\d`)
-	digitRegex     = regexp.MustCompile(`\d|one|two|three|four|five|six|seven|eight|nine`)
-	revDigitRegex  = regexp.MustCompile(`\d|enin|thgie|neves|xis|evif|ruof|eerht|owt|eno`)
-	words          = map[string]int{
-		"one":   1,
-		"two":   2,
-		"three": 3,
-		"four":  4,
-		"five":  5,
-		"six":   6,
-		"seven": 7,
-		"eight": 8,
-		"nine":  9,
-		"enin":  9,
-		"thgie": 8,
-		"neves": 7,
-		"xis":   6,
-		"evif":  5,
-		"ruof":  4,
-		"eerht": 3,
-		"owt":   2,
-		"eno":   1,
+func main() {
+	tokenSpec := flag.String("tokens", "english", "tokens for part 2, a builtin set (digits, english, german, french) or word=value pairs")
+	flag.Parse()
+
+	tokens, err := parseTokenSet(*tokenSpec)
+	if err != nil {
+		log.Fatalln(err)
 	}
-)
+	digitMatcher := NewMatcher(digitTokens)
+	tokenMatcher := NewMatcher(tokens)
 
-func main() {
 	file, err := os.Open(puzzleInput)
 	if err != nil {
 		log.Fatalln(err)
@@ -57,41 +39,16 @@ func main() {
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 		s := scanner.Bytes()
-		match := digitOnlyRegex.Find(s)
-		if len(match) == 0 {
-			log.Fatalln("not enough digits")
-		}
-		firstDigit, err := parseValue(string(match))
-		if err != nil {
-			log.Fatalln(err)
-		}
-		match = digitRegex.Find(s)
-		if len(match) == 0 {
+		firstDigit, lastDigit, ok := digitMatcher.FirstLast(s)
+		if !ok {
 			log.Fatalln("not enough digits")
 		}
-		first, err := parseValue(string(match))
-		if err != nil {
-			log.Fatalln(err)
-		}
-		slices.Reverse(s)
-		match = digitOnlyRegex.Find(s)
-		if len(match) == 0 {
+		first, last, ok := tokenMatcher.FirstLast(s)
+		if !ok {
 			log.Fatalln("not enough digits")
 		}
-		lastDigit, err := parseValue(string(match))
-		if err != nil {
-			log.Fatalln(err)
-		}
-		match = revDigitRegex.Find(s)
-		if len(match) == 0 {
-			log.Fatalln("not enough reverse digits")
-		}
-		last, err := parseValue(string(match))
-		if err != nil {
-			log.Fatalln(err)
-		}
-		sum1 += firstDigit*10 + lastDigit
-		sum2 += first*10 + last
+		sum1 += firstDigit.Value*10 + lastDigit.Value
+		sum2 += first.Value*10 + last.Value
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -101,10 +58,3 @@ func main() {
 	fmt.Println("Part 1:", sum1)
 	fmt.Println("Part 2:", sum2)
 }
-
-func parseValue(s string) (int, error) {
-	if v, ok := words[s]; ok {
-		return v, nil
-	}
-	return strconv.Atoi(s)
-}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Match is an occurrence of a token at s[Start:End].
+	Match struct {
+		Start int
+		End   int
+		Value int
+	}
+
+	// Matcher is an Aho–Corasick automaton over a set of tokens that finds
+	// overlapping occurrences of every token in one pass. The goto and
+	// failure functions are compiled into a full transition table, so that
+	// each byte of the input is a single lookup.
+	Matcher struct {
+		next [][256]int32
+		// out is the token ending at a state, or -1
+		out []int32
+		// dict is the nearest state along the failure links that ends a token,
+		// or -1
+		dict   []int32
+		lens   []int
+		values []int
+	}
+)
+
+var (
+	digitTokens = map[string]int{
+		"0": 0, "1": 1, "2": 2, "3": 3, "4": 4, "5": 5, "6": 6, "7": 7, "8": 8, "9": 9,
+	}
+
+	tokenSets = map[string]map[string]int{
+		"digits": digitTokens,
+		"english": withDigits(map[string]int{
+			"one":   1,
+			"two":   2,
+			"three": 3,
+			"four":  4,
+			"five":  5,
+			"six":   6,
+			"seven": 7,
+			"eight": 8,
+			"nine":  9,
+		}),
+		"german": withDigits(map[string]int{
+			"eins":   1,
+			"zwei":   2,
+			"drei":   3,
+			"vier":   4,
+			"fuenf":  5,
+			"sechs":  6,
+			"sieben": 7,
+			"acht":   8,
+			"neun":   9,
+		}),
+		"french": withDigits(map[string]int{
+			"un":     1,
+			"deux":   2,
+			"trois":  3,
+			"quatre": 4,
+			"cinq":   5,
+			"six":    6,
+			"sept":   7,
+			"huit":   8,
+			"neuf":   9,
+		}),
+	}
+)
+
+func withDigits(words map[string]int) map[string]int {
+	res := make(map[string]int, len(words)+len(digitTokens))
+	for k, v := range digitTokens {
+		res[k] = v
+	}
+	for k, v := range words {
+		res[k] = v
+	}
+	return res
+}
+
+var errTokenSet = errors.New("Invalid token set")
+
+// parseTokenSet returns a builtin token set by name, or the digits along
+// with comma separated word=value pairs such as "one=1,two=2".
+func parseTokenSet(spec string) (map[string]int, error) {
+	if t, ok := tokenSets[spec]; ok {
+		return t, nil
+	}
+	if !strings.Contains(spec, "=") {
+		return nil, fmt.Errorf("%w: unknown token set %q", errTokenSet, spec)
+	}
+	words := map[string]int{}
+	for _, i := range strings.Split(spec, ",") {
+		word, value, ok := strings.Cut(i, "=")
+		if !ok || word == "" {
+			return nil, fmt.Errorf("%w: malformed token %q", errTokenSet, i)
+		}
+		num, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: token %q: %w", errTokenSet, i, err)
+		}
+		words[word] = num
+	}
+	return withDigits(words), nil
+}
+
+// NewMatcher builds the trie of the tokens and then computes the failure
+// links breadth first, filling in every missing transition from the state
+// the failure link points to.
+func NewMatcher(tokens map[string]int) *Matcher {
+	m := &Matcher{}
+	m.addState()
+	// sorted for a deterministic numbering of states
+	keys := make([]string, 0, len(tokens))
+	for k := range tokens {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		var s int32
+		for i := 0; i < len(k); i++ {
+			t := m.next[s][k[i]]
+			if t == 0 {
+				t = m.addState()
+				m.next[s][k[i]] = t
+			}
+			s = t
+		}
+		m.out[s] = int32(len(m.values))
+		m.lens = append(m.lens, len(k))
+		m.values = append(m.values, tokens[k])
+	}
+
+	fail := make([]int32, len(m.next))
+	queue := make([]int32, 0, len(m.next))
+	for c := range m.next[0] {
+		if t := m.next[0][c]; t != 0 {
+			queue = append(queue, t)
+		}
+	}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		f := fail[s]
+		if m.out[f] >= 0 {
+			m.dict[s] = f
+		} else {
+			m.dict[s] = m.dict[f]
+		}
+		for c := range m.next[s] {
+			t := m.next[s][c]
+			if t == 0 {
+				m.next[s][c] = m.next[f][c]
+				continue
+			}
+			fail[t] = m.next[f][c]
+			queue = append(queue, t)
+		}
+	}
+	return m
+}
+
+func (m *Matcher) addState() int32 {
+	m.next = append(m.next, [256]int32{})
+	m.out = append(m.out, -1)
+	m.dict = append(m.dict, -1)
+	return int32(len(m.next) - 1)
+}
+
+// FirstLast returns the occurrences of tokens that start first and last in
+// s, where occurrences may overlap, preferring the longer token of those that
+// start at the same index. It reports false if no token occurs.
+func (m *Matcher) FirstLast(s []byte) (Match, Match, bool) {
+	first := Match{Start: -1}
+	last := Match{Start: -1}
+	var state int32
+	for i, c := range s {
+		state = m.next[state][c]
+		k := state
+		if m.out[k] < 0 {
+			k = m.dict[k]
+		}
+		for ; k >= 0; k = m.dict[k] {
+			t := m.out[k]
+			match := Match{
+				Start: i + 1 - m.lens[t],
+				End:   i + 1,
+				Value: m.values[t],
+			}
+			if first.Start < 0 || match.Start < first.Start || match.Start == first.Start && match.End > first.End {
+				first = match
+			}
+			if match.Start > last.Start || match.Start == last.Start && match.End > last.End {
+				last = match
+			}
+		}
+	}
+	return first, last, first.Start >= 0
+}
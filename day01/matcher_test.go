@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func sumCalibration(t *testing.T, m *Matcher, lines string) int {
+	t.Helper()
+	sum := 0
+	for _, i := range strings.Fields(lines) {
+		first, last, ok := m.FirstLast([]byte(i))
+		if !ok {
+			t.Fatalf("Invalid line %q has no tokens", i)
+		}
+		sum += first.Value*10 + last.Value
+	}
+	return sum
+}
+
+func TestMatcherExample(t *testing.T) {
+	for tcn, tc := range []struct {
+		tokens string
+		lines  string
+		exp    int
+	}{
+		{
+			tokens: "digits",
+			lines: `
+1abc2
+pqr3stu8vwx
+a1b2c3d4e5f
+treb7uchet
+`,
+			exp: 142,
+		},
+		{
+			tokens: "english",
+			lines: `
+two1nine
+eightwothree
+abcone2threexyz
+xtwone3four
+4nineeightseven2
+zoneight234
+7pqrstsixteen
+`,
+			exp: 281,
+		},
+	} {
+		tc := tc
+		t.Run("calibration test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			if v := sumCalibration(t, NewMatcher(tokenSets[tc.tokens]), tc.lines); v != tc.exp {
+				t.Fatalf("Invalid output %d != %d", v, tc.exp)
+			}
+		})
+	}
+}
+
+func TestMatcherFirstLast(t *testing.T) {
+	for tcn, tc := range []struct {
+		tokens      string
+		s           string
+		first, last Match
+		ok          bool
+	}{
+		{tokens: "english", s: "xtwone", first: Match{Start: 1, End: 4, Value: 2}, last: Match{Start: 3, End: 6, Value: 1}, ok: true},
+		{tokens: "english", s: "oneight", first: Match{Start: 0, End: 3, Value: 1}, last: Match{Start: 2, End: 7, Value: 8}, ok: true},
+		{tokens: "english", s: "abc", ok: false},
+		{tokens: "french", s: "huitrois", first: Match{Start: 0, End: 4, Value: 8}, last: Match{Start: 3, End: 8, Value: 3}, ok: true},
+		{tokens: "german", s: "seinsiebenx", first: Match{Start: 1, End: 5, Value: 1}, last: Match{Start: 4, End: 10, Value: 7}, ok: true},
+		// the longer of the tokens starting at the same index wins
+		{tokens: "ab=1,abcd=2,bc=3", s: "abcd", first: Match{Start: 0, End: 4, Value: 2}, last: Match{Start: 1, End: 3, Value: 3}, ok: true},
+		{tokens: "uno=1,dos=2", s: "9unodos", first: Match{Start: 0, End: 1, Value: 9}, last: Match{Start: 4, End: 7, Value: 2}, ok: true},
+	} {
+		tc := tc
+		t.Run("first last test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			tokens, err := parseTokenSet(tc.tokens)
+			if err != nil {
+				t.Fatal(err)
+			}
+			first, last, ok := NewMatcher(tokens).FirstLast([]byte(tc.s))
+			if ok != tc.ok {
+				t.Fatalf("Invalid ok %t != %t", ok, tc.ok)
+			}
+			if ok && (first != tc.first || last != tc.last) {
+				t.Fatalf("Invalid output %+v %+v != %+v %+v", first, last, tc.first, tc.last)
+			}
+		})
+	}
+}
+
+func TestParseTokenSetInvalid(t *testing.T) {
+	for tcn, tc := range []struct {
+		spec string
+	}{
+		{spec: "klingon"},
+		{spec: "one=1,two"},
+		{spec: "=1"},
+		{spec: "one=x"},
+	} {
+		tc := tc
+		t.Run("token set test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			if _, err := parseTokenSet(tc.spec); !errors.Is(err, errTokenSet) {
+				t.Fatalf("Invalid error %v != %v", err, errTokenSet)
+			}
+		})
+	}
+}
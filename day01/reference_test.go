@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"math/rand"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"testing"
+	"testing/quick"
+)
+
+var (
+	digitOnlyRegex = regexp.MustCompile(`\d`)
+	digitRegex     = regexp.MustCompile(`\d|one|two|three|four|five|six|seven|eight|nine`)
+	revDigitRegex  = regexp.MustCompile(`\d|enin|thgie|neves|xis|evif|ruof|eerht|owt|eno`)
+	words          = map[string]int{
+		"one":   1,
+		"two":   2,
+		"three": 3,
+		"four":  4,
+		"five":  5,
+		"six":   6,
+		"seven": 7,
+		"eight": 8,
+		"nine":  9,
+		"enin":  9,
+		"thgie": 8,
+		"neves": 7,
+		"xis":   6,
+		"evif":  5,
+		"ruof":  4,
+		"eerht": 3,
+		"owt":   2,
+		"eno":   1,
+	}
+)
+
+func parseValueRef(s string) (int, error) {
+	if v, ok := words[s]; ok {
+		return v, nil
+	}
+	return strconv.Atoi(s)
+}
+
+// calibrationRef finds the first and last digit, and the first and last
+// digit or english word, by matching the line and then the reversed line.
+func calibrationRef(line []byte) (firstDigit, lastDigit, first, last int, ok bool) {
+	s := slices.Clone(line)
+	find := func(r *regexp.Regexp) int {
+		match := r.Find(s)
+		if len(match) == 0 {
+			ok = false
+			return 0
+		}
+		v, err := parseValueRef(string(match))
+		if err != nil {
+			ok = false
+			return 0
+		}
+		return v
+	}
+	ok = true
+	firstDigit = find(digitOnlyRegex)
+	first = find(digitRegex)
+	slices.Reverse(s)
+	lastDigit = find(digitOnlyRegex)
+	last = find(revDigitRegex)
+	return firstDigit, lastDigit, first, last, ok
+}
+
+func calibration(digits, english *Matcher, line []byte) (firstDigit, lastDigit, first, last int, ok bool) {
+	fd, ld, ok1 := digits.FirstLast(line)
+	f, l, ok2 := english.FirstLast(line)
+	if !ok1 || !ok2 {
+		return 0, 0, 0, 0, false
+	}
+	return fd.Value, ld.Value, f.Value, l.Value, true
+}
+
+type (
+	calibrationLine struct {
+		s []byte
+	}
+)
+
+func (calibrationLine) Generate(r *rand.Rand, size int) reflect.Value {
+	// pieces of number words so that words overlap and nearly match
+	pieces := []string{"o", "n", "e", "t", "w", "h", "r", "i", "g", "s", "x", "v", "f", "u", "ni", "ei", "se", "on", "tw", "1", "7", "0"}
+	var l calibrationLine
+	for i := r.Intn(24); i > 0; i-- {
+		l.s = append(l.s, pieces[r.Intn(len(pieces))]...)
+	}
+	return reflect.ValueOf(l)
+}
+
+func TestMatcherQuick(t *testing.T) {
+	digits := NewMatcher(digitTokens)
+	english := NewMatcher(tokenSets["english"])
+	type result struct {
+		First, Last int
+		Ok          bool
+	}
+	for tcn, tc := range []struct {
+		f   func(l calibrationLine) result
+		ref func(l calibrationLine) result
+	}{
+		{
+			f: func(l calibrationLine) result {
+				first, last, _, _, ok := calibration(digits, english, l.s)
+				return result{First: first, Last: last, Ok: ok}
+			},
+			ref: func(l calibrationLine) result {
+				first, last, _, _, ok := calibrationRef(l.s)
+				if !ok {
+					return result{}
+				}
+				return result{First: first, Last: last, Ok: ok}
+			},
+		},
+		{
+			f: func(l calibrationLine) result {
+				_, _, first, last, ok := calibration(digits, english, l.s)
+				return result{First: first, Last: last, Ok: ok}
+			},
+			ref: func(l calibrationLine) result {
+				_, _, first, last, ok := calibrationRef(l.s)
+				if !ok {
+					return result{}
+				}
+				return result{First: first, Last: last, Ok: ok}
+			},
+		},
+	} {
+		tc := tc
+		t.Run("matcher quick test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			if err := quick.CheckEqual(tc.f, tc.ref, &quick.Config{MaxCount: 2000}); err != nil {
+				t.Fatalf("Invalid output %v", err)
+			}
+		})
+	}
+}
+
+func readLines(b *testing.B) [][]byte {
+	b.Helper()
+	file, err := os.ReadFile(puzzleInput)
+	if err != nil {
+		b.Fatal(err)
+	}
+	var lines [][]byte
+	scanner := bufio.NewScanner(bytes.NewReader(file))
+	for scanner.Scan() {
+		lines = append(lines, slices.Clone(scanner.Bytes()))
+	}
+	return lines
+}
+
+func BenchmarkCalibrationRegex(b *testing.B) {
+	lines := readLines(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, j := range lines {
+			calibrationRef(j)
+		}
+	}
+}
+
+func BenchmarkCalibrationMatcher(b *testing.B) {
+	lines := readLines(b)
+	digits := NewMatcher(digitTokens)
+	english := NewMatcher(tokenSets["english"])
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, j := range lines {
+			calibration(digits, english, j)
+		}
+	}
+}
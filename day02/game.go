@@ -0,0 +1,201 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type (
+	Color string
+
+	// ColorSet is a sorted set of colors.
+	ColorSet []Color
+
+	// Bag is the number of cubes of each color, where a color that is
+	// missing has none.
+	Bag map[Color]int
+
+	// Game is a game record, where each round is the number of cubes of each
+	// color revealed.
+	Game struct {
+		ID     int
+		Rounds []map[Color]int
+	}
+)
+
+var errGame = errors.New("Invalid game")
+
+// NewColorSet returns the set of the colors.
+func NewColorSet(colors ...Color) ColorSet {
+	s := slices.Clone(colors)
+	slices.Sort(s)
+	return slices.Compact(s)
+}
+
+// Union returns the colors in either set.
+func (s ColorSet) Union(t ColorSet) ColorSet {
+	return NewColorSet(append(slices.Clone(s), t...)...)
+}
+
+func (s ColorSet) Contains(c Color) bool {
+	_, ok := slices.BinarySearch(s, c)
+	return ok
+}
+
+// parseCubes parses counts of colors such as "3 blue, 4 red".
+func parseCubes(s string) (map[Color]int, error) {
+	res := map[Color]int{}
+	for _, i := range strings.Split(s, ", ") {
+		a, b, ok := strings.Cut(i, " ")
+		if !ok || b == "" || strings.ContainsAny(b, ",;: ") {
+			return nil, fmt.Errorf("%w: invalid cubes %q", errGame, i)
+		}
+		count, err := strconv.Atoi(a)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid cubes %q: %w", errGame, i, err)
+		}
+		if count < 0 {
+			return nil, fmt.Errorf("%w: negative cubes %q", errGame, i)
+		}
+		c := Color(b)
+		if _, ok := res[c]; ok {
+			return nil, fmt.Errorf("%w: repeated color %q in %q", errGame, c, s)
+		}
+		res[c] = count
+	}
+	return res, nil
+}
+
+// ParseGame parses a game record such as
+// "Game 1: 3 blue, 4 red; 1 red, 2 green".
+func ParseGame(line string) (Game, error) {
+	a, b, ok := strings.Cut(line, ": ")
+	if !ok {
+		return Game{}, fmt.Errorf("%w: invalid line %q", errGame, line)
+	}
+	idStr, ok := strings.CutPrefix(a, "Game ")
+	if !ok {
+		return Game{}, fmt.Errorf("%w: invalid game id %q", errGame, a)
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return Game{}, fmt.Errorf("%w: invalid game id %q: %w", errGame, a, err)
+	}
+	g := Game{ID: id}
+	for _, i := range strings.Split(b, "; ") {
+		round, err := parseCubes(i)
+		if err != nil {
+			return Game{}, fmt.Errorf("game %d: %w", id, err)
+		}
+		g.Rounds = append(g.Rounds, round)
+	}
+	return g, nil
+}
+
+// ParseBag parses the contents of a bag such as "12 red, 13 green".
+func ParseBag(s string) (Bag, error) {
+	cubes, err := parseCubes(s)
+	if err != nil {
+		return nil, err
+	}
+	return Bag(cubes), nil
+}
+
+// Colors returns the colors revealed in any round.
+func (g Game) Colors() ColorSet {
+	var colors []Color
+	for _, i := range g.Rounds {
+		for c := range i {
+			colors = append(colors, c)
+		}
+	}
+	return NewColorSet(colors...)
+}
+
+// PossibleWith reports whether every round could have been drawn from the
+// bag.
+func (g Game) PossibleWith(bag Bag) bool {
+	for _, i := range g.Rounds {
+		for c, n := range i {
+			if n > bag[c] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// MinBag returns the fewest cubes of each color that make the game possible.
+func (g Game) MinBag() Bag {
+	bag := Bag{}
+	for _, i := range g.Rounds {
+		for c, n := range i {
+			bag[c] = max(bag[c], n)
+		}
+	}
+	return bag
+}
+
+// Colors returns the colors in the bag.
+func (b Bag) Colors() ColorSet {
+	colors := make([]Color, 0, len(b))
+	for c := range b {
+		colors = append(colors, c)
+	}
+	return NewColorSet(colors...)
+}
+
+// Power returns the product of the number of cubes of each of the colors.
+func (b Bag) Power(colors ColorSet) int {
+	p := 1
+	for _, c := range colors {
+		p *= b[c]
+	}
+	return p
+}
+
+func (b Bag) String() string {
+	var s strings.Builder
+	for n, c := range b.Colors() {
+		if n > 0 {
+			s.WriteString(", ")
+		}
+		s.WriteString(strconv.Itoa(b[c]))
+		s.WriteByte(' ')
+		s.WriteString(string(c))
+	}
+	return s.String()
+}
+
+// PossibleGames returns the games that are possible with the bag.
+func PossibleGames(games []Game, bag Bag) []Game {
+	var res []Game
+	for _, i := range games {
+		if i.PossibleWith(bag) {
+			res = append(res, i)
+		}
+	}
+	return res
+}
+
+// SumIDs returns the sum of the ids of the games.
+func SumIDs(games []Game) int {
+	sum := 0
+	for _, i := range games {
+		sum += i.ID
+	}
+	return sum
+}
+
+// TotalPower returns the sum of the power of the minimum bag of each game
+// over the colors.
+func TotalPower(games []Game, colors ColorSet) int {
+	sum := 0
+	for _, i := range games {
+		sum += i.MinBag().Power(colors)
+	}
+	return sum
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const exampleGames = `Game 1: 3 blue, 4 red; 1 red, 2 green, 6 blue; 2 green
+Game 2: 1 blue, 2 green; 3 green, 4 blue, 1 red; 1 green, 1 blue
+Game 3: 8 green, 6 blue, 20 red; 5 blue, 4 red, 13 green; 5 green, 1 red
+Game 4: 1 green, 3 red, 6 blue; 3 green, 6 red; 3 green, 15 blue, 14 red
+Game 5: 6 red, 1 blue, 3 green; 2 blue, 1 red, 2 green`
+
+func parseGames(t *testing.T, s string) []Game {
+	t.Helper()
+	var games []Game
+	for _, i := range strings.Split(s, "\n") {
+		g, err := ParseGame(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		games = append(games, g)
+	}
+	return games
+}
+
+func possibleIDs(games []Game, bag Bag) []int {
+	var ids []int
+	for _, i := range PossibleGames(games, bag) {
+		ids = append(ids, i.ID)
+	}
+	return ids
+}
+
+func TestGamesExample(t *testing.T) {
+	games := parseGames(t, exampleGames)
+	bag, err := ParseBag("12 red, 13 green, 14 blue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := possibleIDs(games, bag); !slices.Equal(v, []int{1, 2, 5}) {
+		t.Fatalf("Invalid output %v != %v", v, []int{1, 2, 5})
+	}
+	if v := SumIDs(PossibleGames(games, bag)); v != 8 {
+		t.Fatalf("Invalid output %d != %d", v, 8)
+	}
+	colors := bag.Colors()
+	if v := TotalPower(games, colors); v != 2286 {
+		t.Fatalf("Invalid output %d != %d", v, 2286)
+	}
+
+	for tcn, tc := range []struct {
+		id     int
+		minBag string
+		power  int
+	}{
+		{id: 1, minBag: "6 blue, 2 green, 4 red", power: 48},
+		{id: 2, minBag: "4 blue, 3 green, 1 red", power: 12},
+		{id: 3, minBag: "6 blue, 13 green, 20 red", power: 1560},
+		{id: 4, minBag: "15 blue, 3 green, 14 red", power: 630},
+		{id: 5, minBag: "2 blue, 3 green, 6 red", power: 36},
+	} {
+		tc := tc
+		t.Run("min bag test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			b := games[tc.id-1].MinBag()
+			if v := b.String(); v != tc.minBag {
+				t.Fatalf("Invalid output %s != %s", v, tc.minBag)
+			}
+			if v := b.Power(colors); v != tc.power {
+				t.Fatalf("Invalid output %d != %d", v, tc.power)
+			}
+		})
+	}
+}
+
+func TestGamesRuntimeColors(t *testing.T) {
+	games := parseGames(t, `Game 7: 2 purple, 1 red; 3 orange
+Game 8: 1 red, 1 green`)
+	if v := games[0].Colors(); !slices.Equal(v, ColorSet{"orange", "purple", "red"}) {
+		t.Fatalf("Invalid output %v != %v", v, ColorSet{"orange", "purple", "red"})
+	}
+
+	for tcn, tc := range []struct {
+		bag string
+		exp []int
+	}{
+		{bag: "12 red, 13 green, 14 blue", exp: []int{8}},
+		{bag: "3 orange, 2 purple, 1 red", exp: []int{7}},
+		{bag: "3 orange, 2 purple, 1 red, 1 green", exp: []int{7, 8}},
+		{bag: "2 orange, 2 purple, 1 red, 1 green", exp: []int{8}},
+	} {
+		tc := tc
+		t.Run("possible games test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			bag, err := ParseBag(tc.bag)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v := possibleIDs(games, bag); !slices.Equal(v, tc.exp) {
+				t.Fatalf("Invalid output %v != %v", v, tc.exp)
+			}
+		})
+	}
+
+	for tcn, tc := range []struct {
+		colors ColorSet
+		exp    int
+	}{
+		{colors: NewColorSet("purple", "orange"), exp: 6},
+		{colors: NewColorSet("purple", "orange").Union(NewColorSet("red")), exp: 6},
+		{colors: NewColorSet("red", "green"), exp: 1},
+	} {
+		tc := tc
+		t.Run("total power test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			if v := TotalPower(games, tc.colors); v != tc.exp {
+				t.Fatalf("Invalid output %d != %d", v, tc.exp)
+			}
+		})
+	}
+}
+
+func TestParseGameInvalid(t *testing.T) {
+	for tcn, tc := range []struct {
+		line string
+	}{
+		{line: "Game 1 3 blue"},
+		{line: "Round 1: 3 blue"},
+		{line: "Game x: 3 blue"},
+		{line: "Game 1: 3blue"},
+		{line: "Game 1: x blue"},
+		{line: "Game 1: -1 blue"},
+		{line: "Game 1: 3 blue, 4 blue"},
+		{line: "Game 1: 3 blue;"},
+	} {
+		tc := tc
+		t.Run("parse game test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			if _, err := ParseGame(tc.line); !errors.Is(err, errGame) {
+				t.Fatalf("Invalid error %v != %v", err, errGame)
+			}
+		})
+	}
+}
@@ -2,11 +2,10 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"log"
 	"os"
-	"regexp"
-	"strconv"
 	"strings"
 )
 
@@ -14,9 +13,25 @@ const (
 	puzzleInput = "input.txt"
 )
 
-var numRegex = regexp.MustCompile(`\d+`)
-
 func main() {
+	bagSpec := flag.String("bag", "12 red, 13 green, 14 blue", "cubes in the bag for part 1")
+	colorSpec := flag.String("colors", "", "comma separated colors of the power for part 2, or the colors of the bag if empty")
+	report := flag.Bool("games", false, "print the minimum bag of every game and whether it is possible with the bag")
+	flag.Parse()
+
+	bag, err := ParseBag(*bagSpec)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	colors := bag.Colors()
+	if *colorSpec != "" {
+		var c []Color
+		for _, i := range strings.Split(*colorSpec, ",") {
+			c = append(c, Color(strings.TrimSpace(i)))
+		}
+		colors = NewColorSet(c...)
+	}
+
 	file, err := os.Open(puzzleInput)
 	if err != nil {
 		log.Fatalln(err)
@@ -27,66 +42,28 @@ func main() {
 		}
 	}()
 
-	sum := 0
-	sum2 := 0
+	var games []Game
 
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
-		a, b, ok := strings.Cut(scanner.Text(), ": ")
-		if !ok {
-			log.Fatalln("Invalid line")
-		}
-		gameNum, err := strconv.Atoi(numRegex.FindString(a))
+		g, err := ParseGame(scanner.Text())
 		if err != nil {
 			log.Fatalln(err)
 		}
-		possible := true
-		maxRed := 0
-		maxGreen := 0
-		maxBlue := 0
-		rounds := strings.Split(b, "; ")
-		for _, i := range rounds {
-			cubes := strings.Split(i, ", ")
-			for _, j := range cubes {
-				a, b, ok := strings.Cut(j, " ")
-				if !ok {
-					log.Fatalln("Invalid cubes")
-				}
-				count, err := strconv.Atoi(a)
-				if err != nil {
-					log.Fatalln(err)
-				}
-				switch b {
-				case "red":
-					if count > 12 {
-						possible = false
-					}
-					maxRed = max(maxRed, count)
-				case "green":
-					if count > 13 {
-						possible = false
-					}
-					maxGreen = max(maxGreen, count)
-				case "blue":
-					if count > 14 {
-						possible = false
-					}
-					maxBlue = max(maxBlue, count)
-				default:
-					log.Fatalln("Invalid color")
-				}
-			}
-		}
-		if possible {
-			sum += gameNum
-		}
-		sum2 += maxRed * maxGreen * maxBlue
+		games = append(games, g)
 	}
 
 	if err := scanner.Err(); err != nil {
 		log.Fatalln(err)
 	}
 
-	fmt.Println("Part 1:", sum)
-	fmt.Println("Part 2:", sum2)
+	if *report {
+		for _, i := range games {
+			minBag := i.MinBag()
+			fmt.Printf("Game %d: possible %t, minimum bag %s, power %d\n", i.ID, i.PossibleWith(bag), minBag, minBag.Power(colors))
+		}
+	}
+
+	fmt.Println("Part 1:", SumIDs(PossibleGames(games, bag)))
+	fmt.Println("Part 2:", TotalPower(games, colors))
 }
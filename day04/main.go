@@ -31,8 +31,7 @@ func main() {
 
 	sum := 0
 
-	totalCards := 0
-	bonusCards := [numSlots]int{}
+	var cards []Card
 
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
@@ -73,14 +72,10 @@ func main() {
 		}
 		sum += points
 
-		slot := (cardNum + 4) % numSlots
-		currentMultiplier := bonusCards[slot] + 1
-		bonusCards[slot] = 0
-		totalCards += currentMultiplier
-		for i := 1; i <= count; i++ {
-			k := (slot + i) % numSlots
-			bonusCards[k] += currentMultiplier
-		}
+		cards = append(cards, Card{
+			Num:     cardNum,
+			Matches: count,
+		})
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -88,5 +83,31 @@ func main() {
 	}
 
 	fmt.Println("Part 1:", sum)
-	fmt.Println("Part 2:", totalCards)
+	fmt.Println("Part 2:", countTotalCards(cards))
+}
+
+type (
+	Card struct {
+		Num     int
+		Matches int
+	}
+)
+
+// countTotalCards counts the original and won copies of consecutively
+// numbered cards. A card can only win copies of the next numSlots cards, so
+// the pending copies are kept in a ring indexed by card number.
+func countTotalCards(cards []Card) int {
+	totalCards := 0
+	bonusCards := [numSlots]int{}
+	for _, c := range cards {
+		slot := (c.Num + 4) % numSlots
+		currentMultiplier := bonusCards[slot] + 1
+		bonusCards[slot] = 0
+		totalCards += currentMultiplier
+		for i := 1; i <= c.Matches; i++ {
+			k := (slot + i) % numSlots
+			bonusCards[k] += currentMultiplier
+		}
+	}
+	return totalCards
 }
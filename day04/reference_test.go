@@ -0,0 +1,64 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+)
+
+type (
+	cardsInput struct {
+		cards []Card
+	}
+)
+
+// Generate creates a short run of consecutively numbered cards which, as in
+// the puzzle, never win copies of cards past the end of the table.
+func (cardsInput) Generate(r *rand.Rand, size int) reflect.Value {
+	n := r.Intn(32) + 1
+	first := r.Intn(100) + 1
+	cards := make([]Card, n)
+	for i := range cards {
+		cards[i] = Card{
+			Num:     first + i,
+			Matches: r.Intn(min(numSlots, n-1-i) + 1),
+		}
+	}
+	return reflect.ValueOf(cardsInput{
+		cards: cards,
+	})
+}
+
+// countTotalCardsRef counts cards by keeping the number of copies of every
+// card.
+func countTotalCardsRef(cards []Card) int {
+	copies := make([]int, len(cards))
+	for i := range copies {
+		copies[i] = 1
+	}
+	total := 0
+	for i, c := range cards {
+		total += copies[i]
+		for j := 1; j <= c.Matches; j++ {
+			copies[i+j] += copies[i]
+		}
+	}
+	return total
+}
+
+func TestCountTotalCards(t *testing.T) {
+	if err := quick.CheckEqual(
+		func(in cardsInput) int {
+			return countTotalCards(in.cards)
+		},
+		func(in cardsInput) int {
+			return countTotalCardsRef(in.cards)
+		},
+		&quick.Config{
+			MaxCount: 4096,
+		},
+	); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/xorkevin/advent2023/interval"
 )
 
 const (
@@ -16,18 +18,6 @@ const (
 
 var digitRegex = regexp.MustCompile(`\d+`)
 
-type (
-	Range struct {
-		Start int
-		End   int
-	}
-
-	Range2 struct {
-		Dest Range
-		Src  Range
-	}
-)
-
 func main() {
 	file, err := os.Open(puzzleInput)
 	if err != nil {
@@ -40,11 +30,11 @@ func main() {
 	}()
 
 	var seeds []int
-	var seeds2 []Range
+	var seeds2 interval.Set
 
-	var rangeMaps [][]Range2
+	var rangeMaps []interval.Map
 
-	var lastRangeMap []Range2
+	var lastRangeMap []interval.Rule
 
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
@@ -58,7 +48,7 @@ func main() {
 				seeds = append(seeds, num)
 			}
 			for i := 1; i < len(seeds); i += 2 {
-				seeds2 = append(seeds2, Range{
+				seeds2.Insert(interval.Range{
 					Start: seeds[i-1],
 					End:   seeds[i-1] + seeds[i],
 				})
@@ -66,7 +56,7 @@ func main() {
 			continue
 		} else if strings.HasSuffix(line, "map:") {
 			if len(lastRangeMap) != 0 {
-				rangeMaps = append(rangeMaps, lastRangeMap)
+				rangeMaps = append(rangeMaps, interval.NewMap(lastRangeMap))
 				lastRangeMap = nil
 			}
 			continue
@@ -89,15 +79,12 @@ func main() {
 		if err != nil {
 			log.Fatalln(err)
 		}
-		lastRangeMap = append(lastRangeMap, Range2{
-			Dest: Range{
-				Start: num1,
-				End:   num1 + num3,
-			},
-			Src: Range{
+		lastRangeMap = append(lastRangeMap, interval.Rule{
+			Src: interval.Range{
 				Start: num2,
 				End:   num2 + num3,
 			},
+			Offset: num1 - num2,
 		})
 	}
 
@@ -106,99 +93,21 @@ func main() {
 	}
 
 	if len(lastRangeMap) != 0 {
-		rangeMaps = append(rangeMaps, lastRangeMap)
+		rangeMaps = append(rangeMaps, interval.NewMap(lastRangeMap))
 		lastRangeMap = nil
 	}
 
-	for _, i := range rangeMaps {
-		seeds = runRange(seeds, i)
-		seeds2 = runRange2(seeds2, i)
+	if len(seeds) == 0 || seeds2.IsEmpty() {
+		log.Fatalln("No seeds")
 	}
 
-	minSeed := seeds[0]
-	for _, i := range seeds {
-		if i < minSeed {
-			minSeed = i
-		}
-	}
-	fmt.Println("Part 1:", minSeed)
-
-	minSeed2 := seeds2[0].Start
-	for _, i := range seeds2 {
-		if i.Start < minSeed2 {
-			minSeed2 = i.Start
-		}
-	}
-	fmt.Println("Part 2:", minSeed2)
-}
+	seedToLocation := interval.Compose(rangeMaps...)
 
-func runRange(seeds []int, rangeMap []Range2) []int {
-	res := make([]int, 0, len(seeds))
+	minSeed := seedToLocation.Apply(seeds[0])
 	for _, i := range seeds {
-		k := i
-		for _, j := range rangeMap {
-			if i >= j.Src.Start && i < j.Src.End {
-				k = i - j.Src.Start + j.Dest.Start
-				break
-			}
-		}
-		res = append(res, k)
+		minSeed = min(minSeed, seedToLocation.Apply(i))
 	}
-	return res
-}
+	fmt.Println("Part 1:", minSeed)
 
-func runRange2(seeds []Range, rangeMap []Range2) []Range {
-	res := make([]Range, 0, len(seeds))
-	otherRanges := make([]Range, 0, len(seeds))
-	for _, i := range seeds {
-		nextStart := i.Start
-		nextEnd := i.End
-		for _, j := range rangeMap {
-			if i.Start >= j.Src.Start && i.Start < j.Src.End {
-				nextStart = i.Start - j.Src.Start + j.Dest.Start
-				if i.End <= j.Src.End {
-					nextEnd = i.End - j.Src.Start + j.Dest.Start
-				} else {
-					nextEnd = j.Dest.End
-					otherRanges = append(otherRanges, Range{
-						Start: j.Src.End,
-						End:   i.End,
-					})
-				}
-				break
-			}
-			if i.End > j.Src.Start && i.End <= j.Src.End {
-				nextEnd = i.End - j.Src.Start + j.Dest.Start
-				nextStart = j.Dest.Start
-				otherRanges = append(otherRanges, Range{
-					Start: i.Start,
-					End:   j.Src.Start,
-				})
-				break
-			}
-			if i.Start < j.Src.Start && i.End > j.Src.End {
-				nextStart = j.Dest.Start
-				nextEnd = j.Dest.End
-				otherRanges = append(otherRanges,
-					Range{
-						Start: i.Start,
-						End:   j.Src.Start,
-					},
-					Range{
-						Start: j.Src.End,
-						End:   i.End,
-					},
-				)
-				break
-			}
-		}
-		res = append(res, Range{
-			Start: nextStart,
-			End:   nextEnd,
-		})
-	}
-	if len(otherRanges) != 0 {
-		res = append(res, runRange2(otherRanges, rangeMap)...)
-	}
-	return res
+	fmt.Println("Part 2:", seedToLocation.ApplySet(seeds2).Ranges()[0].Start)
 }
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"slices"
+	"testing"
+	"testing/quick"
+
+	"github.com/xorkevin/advent2023/interval"
+)
+
+const refDomain = 48
+
+type (
+	almanacInput struct {
+		seeds     []interval.Range
+		rangeMaps [][]interval.Rule
+	}
+)
+
+// Generate creates seed ranges and a chain of maps whose source ranges are
+// disjoint within each map, as in the puzzle.
+func (almanacInput) Generate(r *rand.Rand, size int) reflect.Value {
+	var in almanacInput
+	for i := r.Intn(4) + 1; i > 0; i-- {
+		start := r.Intn(refDomain)
+		in.seeds = append(in.seeds, interval.Range{
+			Start: start,
+			End:   start + r.Intn(refDomain-start) + 1,
+		})
+	}
+	for i := r.Intn(4) + 1; i > 0; i-- {
+		var rangeMap []interval.Rule
+		for cur := r.Intn(8); cur < refDomain; {
+			l := r.Intn(12) + 1
+			if cur+l > refDomain {
+				l = refDomain - cur
+			}
+			rangeMap = append(rangeMap, interval.Rule{
+				Src: interval.Range{
+					Start: cur,
+					End:   cur + l,
+				},
+				Offset: r.Intn(refDomain) - cur,
+			})
+			cur += l + r.Intn(8)
+		}
+		r.Shuffle(len(rangeMap), func(i, j int) {
+			rangeMap[i], rangeMap[j] = rangeMap[j], rangeMap[i]
+		})
+		in.rangeMaps = append(in.rangeMaps, rangeMap)
+	}
+	return reflect.ValueOf(in)
+}
+
+// runRangeRef sends each seed through the first rule whose source contains
+// it.
+func runRangeRef(seeds []int, rangeMap []interval.Rule) []int {
+	res := make([]int, 0, len(seeds))
+	for _, i := range seeds {
+		k := i
+		for _, j := range rangeMap {
+			if i >= j.Src.Start && i < j.Src.End {
+				k = i + j.Offset
+				break
+			}
+		}
+		res = append(res, k)
+	}
+	return res
+}
+
+// runRangesRef maps every seed in the ranges one at a time and returns the
+// sorted set of results.
+func runRangesRef(seeds []interval.Range, rangeMaps [][]interval.Rule) []int {
+	var points []int
+	for _, i := range seeds {
+		for j := i.Start; j < i.End; j++ {
+			points = append(points, j)
+		}
+	}
+	for _, i := range rangeMaps {
+		points = runRangeRef(points, i)
+	}
+	slices.Sort(points)
+	return slices.Compact(points)
+}
+
+func rangesToPoints(ranges []interval.Range) []int {
+	var points []int
+	for _, i := range ranges {
+		for j := i.Start; j < i.End; j++ {
+			points = append(points, j)
+		}
+	}
+	slices.Sort(points)
+	return slices.Compact(points)
+}
+
+func TestComposedMap(t *testing.T) {
+	if err := quick.CheckEqual(
+		func(in almanacInput) []int {
+			maps := make([]interval.Map, len(in.rangeMaps))
+			for n, i := range in.rangeMaps {
+				maps[n] = interval.NewMap(i)
+			}
+			return rangesToPoints(interval.Compose(maps...).ApplySet(interval.NewSet(in.seeds...)).Ranges())
+		},
+		func(in almanacInput) []int {
+			return runRangesRef(in.seeds, in.rangeMaps)
+		},
+		&quick.Config{
+			MaxCount: 4096,
+		},
+	); err != nil {
+		t.Fatal(err)
+	}
+}
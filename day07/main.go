@@ -2,7 +2,9 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"slices"
@@ -16,15 +18,42 @@ const (
 
 type (
 	CardHand struct {
-		Kind   int8
-		KindJ  int8
-		Score  int
-		ScoreJ int
-		Bid    int
+		Cards string
+		Bid   int
+		Line  int
 	}
+
+	rankedHand struct {
+		CardHand
+		Strength HandStrength
+	}
+
+	rulesetsFlag []Ruleset
 )
 
+func (f *rulesetsFlag) String() string {
+	names := make([]string, 0, len(*f))
+	for _, i := range *f {
+		names = append(names, i.Name)
+	}
+	return strings.Join(names, " ")
+}
+
+func (f *rulesetsFlag) Set(s string) error {
+	r, err := ParseRuleset(s)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, r)
+	return nil
+}
+
 func main() {
+	var rulesets rulesetsFlag
+	flag.Var(&rulesets, "rules", "ruleset to score hands with, either standard, joker, or key=value fields name, order, wild, kinds, tie (repeatable)")
+	ties := flag.Bool("ties", false, "list hands that compare equal under each ruleset")
+	flag.Parse()
+
 	file, err := os.Open(puzzleInput)
 	if err != nil {
 		log.Fatalln(err)
@@ -38,7 +67,7 @@ func main() {
 	var hands []CardHand
 
 	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
+	for lineNum := 1; scanner.Scan(); lineNum++ {
 		a, b, ok := strings.Cut(scanner.Text(), " ")
 		if !ok || len(a) != 5 {
 			log.Fatalln("Invalid line")
@@ -47,13 +76,10 @@ func main() {
 		if err != nil {
 			log.Fatalln(err)
 		}
-		ab := []byte(a)
 		hands = append(hands, CardHand{
-			Kind:   handKind(ab, false),
-			KindJ:  handKind(ab, true),
-			Score:  scoreHand(ab, false),
-			ScoreJ: scoreHand(ab, true),
-			Bid:    num,
+			Cards: a,
+			Bid:   num,
+			Line:  lineNum,
 		})
 	}
 
@@ -61,109 +87,103 @@ func main() {
 		log.Fatalln(err)
 	}
 
-	slices.SortFunc(hands, func(a, b CardHand) int {
-		if a.Kind == b.Kind {
-			return a.Score - b.Score
-		}
-		return int(a.Kind - b.Kind)
-	})
-
-	sum := 0
-	for n, i := range hands {
-		sum += (n + 1) * i.Bid
+	labels := []string{"Part 1:", "Part 2:"}
+	if len(rulesets) == 0 {
+		rulesets = builtinRulesets
+	} else {
+		labels = nil
 	}
-	fmt.Println("Part 1:", sum)
 
-	slices.SortFunc(hands, func(a, b CardHand) int {
-		if a.KindJ == b.KindJ {
-			return a.ScoreJ - b.ScoreJ
+	for n, r := range rulesets {
+		ranked, err := rankHands(hands, r)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		label := r.Name + ":"
+		if labels != nil {
+			label = labels[n]
+		}
+		fmt.Println(label, totalWinnings(ranked))
+		if *ties {
+			writeTies(os.Stdout, ranked)
 		}
-		return int(a.KindJ - b.KindJ)
-	})
-
-	sum = 0
-	for n, i := range hands {
-		sum += (n + 1) * i.Bid
 	}
-	fmt.Println("Part 2:", sum)
 }
 
-func scoreCard(b byte, withJoker bool) int {
-	if b >= '2' && b <= '9' {
-		return int(b - '2' + 1)
-	}
-	switch b {
-	case 'T':
-		return 9
-	case 'J':
-		if withJoker {
-			return 0
-		} else {
-			return 10
+// rankHands sorts hands from weakest to strongest under a ruleset. Hands
+// that compare equal keep their input order.
+func rankHands(hands []CardHand, r Ruleset) ([]rankedHand, error) {
+	ranked := make([]rankedHand, 0, len(hands))
+	for _, i := range hands {
+		s, err := r.Strength(i.Cards)
+		if err != nil {
+			return nil, err
 		}
-	case 'Q':
-		return 11
-	case 'K':
-		return 12
-	case 'A':
-		return 13
+		ranked = append(ranked, rankedHand{
+			CardHand: i,
+			Strength: s,
+		})
 	}
-	return 0
+	slices.SortStableFunc(ranked, func(a, b rankedHand) int {
+		return a.Strength.Compare(b.Strength)
+	})
+	return ranked, nil
 }
 
-func scoreHand(b []byte, withJoker bool) int {
+func totalWinnings(ranked []rankedHand) int {
 	sum := 0
-	for _, i := range b {
-		sum = sum*14 + scoreCard(i, withJoker)
+	for n, i := range ranked {
+		sum += (n + 1) * i.Bid
 	}
 	return sum
 }
 
-func handKind(b []byte, withJoker bool) int8 {
-	cardCountsMap := map[byte]int8{}
-	var jokers int8 = 0
-	for _, i := range b {
-		if withJoker && i == 'J' {
-			jokers++
-			continue
+// tiedGroups returns the runs of ranked hands that compare equal, so their
+// relative rank, and therefore the total winnings, depend on input order.
+func tiedGroups(ranked []rankedHand) [][]rankedHand {
+	var groups [][]rankedHand
+	for start := 0; start < len(ranked); {
+		end := start + 1
+		for end < len(ranked) && ranked[end].Strength.Compare(ranked[start].Strength) == 0 {
+			end++
 		}
-		cardCountsMap[i] = cardCountsMap[i] + 1
-	}
-	if len(cardCountsMap) == 0 {
-		return 6
-	}
-	cardCounts := make([]int8, 0, len(cardCountsMap))
-	for _, v := range cardCountsMap {
-		cardCounts = append(cardCounts, v)
-	}
-	slices.Sort(cardCounts)
-	slices.Reverse(cardCounts)
-	cardCounts[0] += jokers
-	if cardCounts[0] == 5 {
-		// 5 of kind
-		return 6
-	}
-	if cardCounts[0] == 4 {
-		// 4 of kind
-		return 5
-	}
-	if cardCounts[0] == 3 {
-		if cardCounts[1] == 2 {
-			// full house
-			return 4
-		} else {
-			// 3 of kind
-			return 3
+		if end-start > 1 {
+			groups = append(groups, ranked[start:end])
 		}
+		start = end
 	}
-	if cardCounts[0] == 2 {
-		if cardCounts[1] == 2 {
-			// two pair
-			return 2
-		} else {
-			// one pair
-			return 1
+	return groups
+}
+
+// writeTies lists each group of tied hands with the ranks the group spans
+// and how far the winnings could move if the group were reordered.
+func writeTies(w io.Writer, ranked []rankedHand) {
+	groups := tiedGroups(ranked)
+	if len(groups) == 0 {
+		fmt.Fprintln(w, "  no ties")
+		return
+	}
+	rank := make(map[int]int, len(ranked))
+	for n, i := range ranked {
+		rank[i.Line] = n + 1
+	}
+	for _, g := range groups {
+		first := rank[g[0].Line]
+		bids := make([]int, 0, len(g))
+		for _, i := range g {
+			bids = append(bids, i.Bid)
+		}
+		// winnings are largest with bids ascending by rank and smallest
+		// with bids descending
+		slices.Sort(bids)
+		hi, lo := 0, 0
+		for n, i := range bids {
+			hi += (first + n) * i
+			lo += (first + len(bids) - 1 - n) * i
+		}
+		fmt.Fprintf(w, "  %d %s hands tied at ranks %d-%d, winnings spread %d\n", len(g), g[0].Strength.Kind, first, first+len(g)-1, hi-lo)
+		for _, i := range g {
+			fmt.Fprintf(w, "    %s bid %d (line %d)\n", i.Cards, i.Bid, i.Line)
 		}
 	}
-	return 0
 }
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type (
+	// HandKind is the type of a hand, such as a full house.
+	HandKind int8
+
+	// TieBreak is the policy that orders hands of the same kind.
+	TieBreak int8
+
+	// Ruleset defines how hands are ranked.
+	Ruleset struct {
+		Name string
+		// Order lists the card labels from weakest to strongest.
+		Order string
+		// Wild lists the card labels that may stand in for any other card
+		// when determining the kind of a hand.
+		Wild string
+		// Precedence lists every hand kind from weakest to strongest.
+		Precedence []HandKind
+		TieBreak   TieBreak
+	}
+
+	// HandStrength orders hands under a ruleset. Hands compare by the Rank
+	// of their Kind in the precedence and then by the tie break Score.
+	HandStrength struct {
+		Kind  HandKind
+		Rank  int
+		Score int
+	}
+)
+
+const (
+	HighCard HandKind = iota
+	OnePair
+	TwoPair
+	ThreeKind
+	FullHouse
+	FourKind
+	FiveKind
+	numHandKinds
+)
+
+const (
+	// TieBreakInOrder compares cards one at a time in the order they were
+	// dealt.
+	TieBreakInOrder TieBreak = iota
+	// TieBreakSorted compares cards one at a time from strongest to weakest,
+	// as in poker.
+	TieBreakSorted
+	// TieBreakNone considers all hands of the same kind equal.
+	TieBreakNone
+)
+
+var handKindNames = [numHandKinds]string{
+	"high",
+	"pair",
+	"twopair",
+	"three",
+	"fullhouse",
+	"four",
+	"five",
+}
+
+func (k HandKind) String() string {
+	if k < 0 || k >= numHandKinds {
+		return fmt.Sprintf("HandKind(%d)", k)
+	}
+	return handKindNames[k]
+}
+
+var tieBreakNames = []string{
+	"order",
+	"sorted",
+	"none",
+}
+
+func (t TieBreak) String() string {
+	if t < 0 || int(t) >= len(tieBreakNames) {
+		return fmt.Sprintf("TieBreak(%d)", t)
+	}
+	return tieBreakNames[t]
+}
+
+var defaultPrecedence = []HandKind{HighCard, OnePair, TwoPair, ThreeKind, FullHouse, FourKind, FiveKind}
+
+var builtinRulesets = []Ruleset{
+	{
+		Name:       "standard",
+		Order:      "23456789TJQKA",
+		Precedence: defaultPrecedence,
+		TieBreak:   TieBreakInOrder,
+	},
+	{
+		Name:       "joker",
+		Order:      "J23456789TQKA",
+		Wild:       "J",
+		Precedence: defaultPrecedence,
+		TieBreak:   TieBreakInOrder,
+	},
+}
+
+var errInvalidRuleset = errors.New("Invalid ruleset")
+
+// ParseRuleset parses either the name of a builtin ruleset or a comma
+// separated list of key=value fields. The keys are name, order, wild, kinds
+// (hand kinds from weakest to strongest separated by '<') and tie (order,
+// sorted, or none). Missing fields are taken from the standard ruleset.
+func ParseRuleset(spec string) (Ruleset, error) {
+	for _, i := range builtinRulesets {
+		if i.Name == spec {
+			return i, nil
+		}
+	}
+	r := builtinRulesets[0]
+	r.Name = spec
+	for _, field := range strings.Split(spec, ",") {
+		k, v, ok := strings.Cut(field, "=")
+		if !ok {
+			return Ruleset{}, fmt.Errorf("%w: unknown ruleset %s", errInvalidRuleset, field)
+		}
+		switch k {
+		case "name":
+			r.Name = v
+		case "order":
+			r.Order = v
+		case "wild":
+			r.Wild = v
+		case "kinds":
+			r.Precedence = nil
+			for _, i := range strings.Split(v, "<") {
+				idx := slices.Index(handKindNames[:], i)
+				if idx < 0 {
+					return Ruleset{}, fmt.Errorf("%w: unknown hand kind %s", errInvalidRuleset, i)
+				}
+				r.Precedence = append(r.Precedence, HandKind(idx))
+			}
+		case "tie":
+			idx := slices.Index(tieBreakNames, v)
+			if idx < 0 {
+				return Ruleset{}, fmt.Errorf("%w: unknown tie break %s", errInvalidRuleset, v)
+			}
+			r.TieBreak = TieBreak(idx)
+		default:
+			return Ruleset{}, fmt.Errorf("%w: unknown field %s", errInvalidRuleset, k)
+		}
+	}
+	if err := r.Validate(); err != nil {
+		return Ruleset{}, err
+	}
+	return r, nil
+}
+
+// Validate checks that the card order has no repeats, that wild cards are in
+// the order, and that the precedence lists every hand kind exactly once.
+func (r Ruleset) Validate() error {
+	for n, i := range []byte(r.Order) {
+		if strings.IndexByte(r.Order[:n], i) >= 0 {
+			return fmt.Errorf("%w: card %c repeated in order", errInvalidRuleset, i)
+		}
+	}
+	for _, i := range []byte(r.Wild) {
+		if strings.IndexByte(r.Order, i) < 0 {
+			return fmt.Errorf("%w: wild card %c not in order", errInvalidRuleset, i)
+		}
+	}
+	if len(r.Precedence) != int(numHandKinds) {
+		return fmt.Errorf("%w: precedence must list all %d hand kinds", errInvalidRuleset, numHandKinds)
+	}
+	for k := HighCard; k < numHandKinds; k++ {
+		if !slices.Contains(r.Precedence, k) {
+			return fmt.Errorf("%w: precedence is missing %s", errInvalidRuleset, k)
+		}
+	}
+	return nil
+}
+
+// kindOfCounts returns the kind of a hand with the given label counts,
+// sorted from largest to smallest.
+func kindOfCounts(counts []int) HandKind {
+	if len(counts) == 0 {
+		return HighCard
+	}
+	second := 0
+	if len(counts) > 1 {
+		second = counts[1]
+	}
+	switch {
+	case counts[0] >= 5:
+		return FiveKind
+	case counts[0] == 4:
+		return FourKind
+	case counts[0] == 3 && second == 2:
+		return FullHouse
+	case counts[0] == 3:
+		return ThreeKind
+	case counts[0] == 2 && second == 2:
+		return TwoPair
+	case counts[0] == 2:
+		return OnePair
+	default:
+		return HighCard
+	}
+}
+
+// Kind returns the strongest kind the hand can be under the precedence of
+// the ruleset. Each wild card may join any group of cards, including a group
+// of other wild cards pretending to be a label absent from the hand, so every
+// reachable kind is tried.
+func (r Ruleset) Kind(cards string) HandKind {
+	countsMap := map[byte]int{}
+	wilds := 0
+	for _, i := range []byte(cards) {
+		if strings.IndexByte(r.Wild, i) >= 0 {
+			wilds++
+			continue
+		}
+		countsMap[i]++
+	}
+	counts := make([]int, 0, len(countsMap)+wilds)
+	for _, v := range countsMap {
+		counts = append(counts, v)
+	}
+	best := -1
+	var bestKind HandKind
+	r.assignWilds(counts, wilds, func(counts []int) {
+		sorted := slices.Clone(counts)
+		slices.Sort(sorted)
+		slices.Reverse(sorted)
+		kind := kindOfCounts(sorted)
+		if p := slices.Index(r.Precedence, kind); p > best {
+			best = p
+			bestKind = kind
+		}
+	})
+	return bestKind
+}
+
+func (r Ruleset) assignWilds(counts []int, wilds int, fn func(counts []int)) {
+	if wilds == 0 {
+		fn(counts)
+		return
+	}
+	for n := range counts {
+		counts[n]++
+		r.assignWilds(counts, wilds-1, fn)
+		counts[n]--
+	}
+	r.assignWilds(append(counts, 1), wilds-1, fn)
+}
+
+// Strength returns the strength of a hand under the ruleset, or an error if
+// the hand has a card that is not in the card order.
+func (r Ruleset) Strength(cards string) (HandStrength, error) {
+	values := make([]int, 0, len(cards))
+	for _, i := range []byte(cards) {
+		v := strings.IndexByte(r.Order, i)
+		if v < 0 {
+			return HandStrength{}, fmt.Errorf("%w: card %c of hand %s is not in ruleset %s", errInvalidRuleset, i, cards, r.Name)
+		}
+		values = append(values, v)
+	}
+	switch r.TieBreak {
+	case TieBreakSorted:
+		slices.Sort(values)
+		slices.Reverse(values)
+	case TieBreakNone:
+		values = nil
+	}
+	score := 0
+	for _, i := range values {
+		score = score*len(r.Order) + i
+	}
+	kind := r.Kind(cards)
+	return HandStrength{
+		Kind:  kind,
+		Rank:  slices.Index(r.Precedence, kind),
+		Score: score,
+	}, nil
+}
+
+// Compare orders hand strengths from weakest to strongest.
+func (s HandStrength) Compare(o HandStrength) int {
+	if s.Rank != o.Rank {
+		return s.Rank - o.Rank
+	}
+	return s.Score - o.Score
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+var exampleHands = []CardHand{
+	{Cards: "32T3K", Bid: 765, Line: 1},
+	{Cards: "T55J5", Bid: 684, Line: 2},
+	{Cards: "KK677", Bid: 28, Line: 3},
+	{Cards: "KTJJT", Bid: 220, Line: 4},
+	{Cards: "QQQJA", Bid: 483, Line: 5},
+}
+
+func TestRulesetWinnings(t *testing.T) {
+	for _, tc := range []struct {
+		spec     string
+		winnings int
+	}{
+		{spec: "standard", winnings: 6440},
+		{spec: "joker", winnings: 5905},
+		// sorted, QQQJA is AQQQJ and still beats JT555, and KK677 is KK776
+		// and still beats KTTJJ
+		{spec: "name=poker,tie=sorted", winnings: 6440},
+	} {
+		tc := tc
+		t.Run(tc.spec, func(t *testing.T) {
+			r, err := ParseRuleset(tc.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ranked, err := rankHands(exampleHands, r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v := totalWinnings(ranked); v != tc.winnings {
+				t.Fatalf("Invalid winnings %d != %d", v, tc.winnings)
+			}
+		})
+	}
+}
+
+func TestRulesetKind(t *testing.T) {
+	for tcn, tc := range []struct {
+		spec  string
+		cards string
+		kind  HandKind
+	}{
+		{spec: "joker", cards: "QJJQ2", kind: FourKind},
+		{spec: "joker", cards: "JJJJJ", kind: FiveKind},
+		{spec: "joker", cards: "2345J", kind: OnePair},
+		{spec: "wild=JQ", cards: "QJ234", kind: ThreeKind},
+		// with two pair ranked above everything else, the wild card makes a
+		// second pair instead of joining the first
+		{spec: "wild=J,kinds=high<pair<three<fullhouse<four<five<twopair", cards: "22J34", kind: TwoPair},
+		{spec: "wild=J,kinds=high<pair<three<fullhouse<four<five<twopair", cards: "2JJ34", kind: TwoPair},
+	} {
+		tc := tc
+		t.Run("kind test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			r, err := ParseRuleset(tc.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v := r.Kind(tc.cards); v != tc.kind {
+				t.Fatalf("Invalid kind %s != %s", v, tc.kind)
+			}
+		})
+	}
+}
+
+func TestTiedGroups(t *testing.T) {
+	r, err := ParseRuleset("name=flat,tie=none")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ranked, err := rankHands(exampleHands, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups := tiedGroups(ranked)
+	// T55J5 and QQQJA are three of a kind, KK677 and KTJJT are two pair
+	if len(groups) != 2 {
+		t.Fatalf("Invalid tie groups %v", groups)
+	}
+	for _, g := range groups {
+		if len(g) != 2 {
+			t.Fatalf("Invalid tie group %v", g)
+		}
+	}
+}
+
+func TestParseRulesetInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"unknown",
+		"order=2234",
+		"wild=X",
+		"kinds=high<pair",
+		"tie=random",
+	} {
+		if _, err := ParseRuleset(spec); err == nil {
+			t.Fatalf("Expected error for %s", spec)
+		}
+	}
+}
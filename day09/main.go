@@ -2,12 +2,12 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"log"
+	"math/big"
 	"os"
 	"regexp"
-	"slices"
-	"strconv"
 )
 
 const (
@@ -17,6 +17,10 @@ const (
 var digitRegex = regexp.MustCompile(`-?\d+`)
 
 func main() {
+	steps := flag.Int64("steps", 1, "number of steps to extrapolate ahead and behind each sequence")
+	report := flag.Bool("report", false, "print the fitted polynomial of each sequence")
+	flag.Parse()
+
 	file, err := os.Open(puzzleInput)
 	if err != nil {
 		log.Fatalln(err)
@@ -27,23 +31,31 @@ func main() {
 		}
 	}()
 
-	sum := 0
-	sum2 := 0
+	sum := new(big.Int)
+	sum2 := new(big.Int)
 
 	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
+	for lineNum := 1; scanner.Scan(); lineNum++ {
 		numStrs := digitRegex.FindAllString(scanner.Text(), -1)
-		nums := make([]int, 0, len(numStrs))
+		nums := make([]*big.Int, 0, len(numStrs))
 		for _, i := range numStrs {
-			num, err := strconv.Atoi(i)
-			if err != nil {
-				log.Fatalln(err)
+			num, ok := new(big.Int).SetString(i, 10)
+			if !ok {
+				log.Fatalln("Invalid number", i)
 			}
 			nums = append(nums, num)
 		}
-		sum += findNextSeq(nums)
-		slices.Reverse(nums)
-		sum2 += findNextSeq(nums)
+		p := FitSequence(nums)
+		if !p.Exact {
+			log.Printf("Line %d never reaches a zero difference row, extrapolating its degree %d interpolant\n", lineNum, p.Degree)
+		}
+		next := p.Next(*steps)
+		prev := p.Prev(*steps)
+		if *report {
+			fmt.Printf("Line %d: degree %d, p(x) = %s, next %s, prev %s\n", lineNum, p.Degree, p, next, prev)
+		}
+		sum.Add(sum, next)
+		sum2.Add(sum2, prev)
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -53,22 +65,3 @@ func main() {
 	fmt.Println("Part 1:", sum)
 	fmt.Println("Part 2:", sum2)
 }
-
-func findNextSeq(nums []int) int {
-	numsAllZero := true
-	for _, i := range nums {
-		if i != 0 {
-			numsAllZero = false
-			break
-		}
-	}
-	if numsAllZero {
-		return 0
-	}
-	next := make([]int, len(nums)-1)
-	for i := 1; i < len(nums); i++ {
-		next[i-1] = nums[i] - nums[i-1]
-	}
-	nextDiff := findNextSeq(next)
-	return nums[len(nums)-1] + nextDiff
-}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"math/big"
+	"strings"
+)
+
+type (
+	// Polynomial is the polynomial through the points (x, Values[x]) for x
+	// from 0 to len(Values)-1, stored in Newton forward difference form.
+	Polynomial struct {
+		// Diffs holds the leading entry of each row of the difference table,
+		// so that p(x) = sum Diffs[k] * C(x, k).
+		Diffs []*big.Int
+		// Len is the number of values the polynomial was fit to.
+		Len int
+		// Degree is the degree of the polynomial, or -1 for the zero
+		// polynomial.
+		Degree int
+		// Exact reports whether the difference table reached a row of zeros.
+		// Otherwise the values are not enough to determine the polynomial,
+		// and it is only the unique interpolant of degree Len-1.
+		Exact bool
+	}
+)
+
+// FitSequence fits a polynomial to a sequence by building its difference
+// table until a row is all zero or only one entry remains.
+func FitSequence(nums []*big.Int) Polynomial {
+	row := make([]*big.Int, len(nums))
+	for n, i := range nums {
+		row[n] = new(big.Int).Set(i)
+	}
+	p := Polynomial{
+		Len:    len(nums),
+		Degree: -1,
+	}
+	for len(row) > 0 {
+		if isZeroRow(row) {
+			p.Exact = true
+			break
+		}
+		p.Diffs = append(p.Diffs, row[0])
+		next := make([]*big.Int, len(row)-1)
+		for i := range next {
+			next[i] = new(big.Int).Sub(row[i+1], row[i])
+		}
+		row = next
+	}
+	for n, i := range p.Diffs {
+		if i.Sign() != 0 {
+			p.Degree = n
+		}
+	}
+	return p
+}
+
+func isZeroRow(row []*big.Int) bool {
+	for _, i := range row {
+		if i.Sign() != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// At returns p(x). Since every C(x, k) is an integer for integer x, the
+// value is computed exactly for x outside of the fitted range as well.
+func (p Polynomial) At(x int64) *big.Int {
+	sum := new(big.Int)
+	binom := big.NewInt(1)
+	bx := big.NewInt(x)
+	term := new(big.Int)
+	for k, d := range p.Diffs {
+		if k > 0 {
+			// C(x, k) = C(x, k-1) * (x-k+1) / k divides exactly
+			binom.Mul(binom, term.Sub(bx, big.NewInt(int64(k-1))))
+			binom.Quo(binom, big.NewInt(int64(k)))
+		}
+		sum.Add(sum, term.Mul(d, binom))
+	}
+	return sum
+}
+
+// Next returns the value k steps after the last fitted value.
+func (p Polynomial) Next(k int64) *big.Int {
+	return p.At(int64(p.Len-1) + k)
+}
+
+// Prev returns the value k steps before the first fitted value.
+func (p Polynomial) Prev(k int64) *big.Int {
+	return p.At(-k)
+}
+
+// Coeffs returns the coefficients of p in the power basis, where Coeffs()[i]
+// is the coefficient of x^i. Each C(x, k) is expanded as the falling
+// factorial x(x-1)...(x-k+1) divided by k!.
+func (p Polynomial) Coeffs() []*big.Rat {
+	res := make([]*big.Rat, len(p.Diffs))
+	for i := range res {
+		res[i] = new(big.Rat)
+	}
+	// falling holds the coefficients of x(x-1)...(x-k+1)
+	falling := []*big.Int{big.NewInt(1)}
+	fact := big.NewInt(1)
+	for k, d := range p.Diffs {
+		if k > 0 {
+			next := make([]*big.Int, k+1)
+			for i := range next {
+				next[i] = new(big.Int)
+			}
+			c := big.NewInt(int64(k - 1))
+			for i, a := range falling {
+				next[i+1].Add(next[i+1], a)
+				next[i].Sub(next[i], new(big.Int).Mul(a, c))
+			}
+			falling = next
+			fact.Mul(fact, big.NewInt(int64(k)))
+		}
+		for i, a := range falling {
+			term := new(big.Rat).SetFrac(new(big.Int).Mul(d, a), fact)
+			res[i].Add(res[i], term)
+		}
+	}
+	return res[:p.Degree+1]
+}
+
+// String formats p in the power basis from the highest degree term down.
+func (p Polynomial) String() string {
+	coeffs := p.Coeffs()
+	var b strings.Builder
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		c := coeffs[i]
+		if c.Sign() == 0 {
+			continue
+		}
+		if b.Len() > 0 {
+			if c.Sign() < 0 {
+				b.WriteString(" - ")
+			} else {
+				b.WriteString(" + ")
+			}
+			c = new(big.Rat).Abs(c)
+		}
+		if c.IsInt() {
+			if i == 0 || !c.Num().IsInt64() || c.Num().Int64() != 1 {
+				b.WriteString(c.Num().String())
+			}
+		} else {
+			b.WriteString("(" + c.RatString() + ")")
+		}
+		switch i {
+		case 0:
+		case 1:
+			b.WriteString("x")
+		default:
+			b.WriteString("x^")
+			b.WriteString(big.NewInt(int64(i)).String())
+		}
+	}
+	if b.Len() == 0 {
+		return "0"
+	}
+	return b.String()
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"math/big"
+	"math/rand"
+	"reflect"
+	"slices"
+	"strconv"
+	"testing"
+	"testing/quick"
+)
+
+func bigInts(nums ...int64) []*big.Int {
+	res := make([]*big.Int, 0, len(nums))
+	for _, i := range nums {
+		res = append(res, big.NewInt(i))
+	}
+	return res
+}
+
+func TestFitSequence(t *testing.T) {
+	for tcn, tc := range []struct {
+		nums   []int64
+		degree int
+		exact  bool
+		coeffs []string
+		next   int64
+		prev   int64
+	}{
+		{nums: []int64{0, 3, 6, 9, 12, 15}, degree: 1, exact: true, coeffs: []string{"0", "3"}, next: 18, prev: -3},
+		{nums: []int64{1, 3, 6, 10, 15, 21}, degree: 2, exact: true, coeffs: []string{"1", "3/2", "1/2"}, next: 28, prev: 0},
+		{nums: []int64{10, 13, 16, 21, 30, 45}, degree: 3, exact: true, coeffs: []string{"10", "11/3", "-1", "1/3"}, next: 68, prev: 5},
+		{nums: []int64{0, 0, 0}, degree: -1, exact: true, coeffs: []string{}, next: 0, prev: 0},
+		// the difference table runs out of rows before reaching zero
+		{nums: []int64{1, 2, 4, 8}, degree: 3, exact: false, coeffs: []string{"1", "5/6", "0", "1/6"}, next: 15, prev: 0},
+	} {
+		tc := tc
+		t.Run("fit test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			p := FitSequence(bigInts(tc.nums...))
+			if p.Degree != tc.degree {
+				t.Fatalf("Invalid degree %d != %d", p.Degree, tc.degree)
+			}
+			if p.Exact != tc.exact {
+				t.Fatalf("Invalid exact %t != %t", p.Exact, tc.exact)
+			}
+			coeffs := make([]string, 0, len(tc.coeffs))
+			for _, i := range p.Coeffs() {
+				coeffs = append(coeffs, i.RatString())
+			}
+			if !slices.Equal(coeffs, tc.coeffs) {
+				t.Fatalf("Invalid coefficients %v != %v", coeffs, tc.coeffs)
+			}
+			if v := p.Next(1); v.Cmp(big.NewInt(tc.next)) != 0 {
+				t.Fatalf("Invalid next %s != %d", v, tc.next)
+			}
+			if v := p.Prev(1); v.Cmp(big.NewInt(tc.prev)) != 0 {
+				t.Fatalf("Invalid prev %s != %d", v, tc.prev)
+			}
+		})
+	}
+}
+
+type (
+	polyInput struct {
+		coeffs []int64
+		n      int
+		k      int64
+	}
+)
+
+// Generate creates an integer polynomial and enough samples to determine it.
+func (polyInput) Generate(r *rand.Rand, size int) reflect.Value {
+	coeffs := make([]int64, r.Intn(6))
+	for i := range coeffs {
+		coeffs[i] = int64(r.Intn(21) - 10)
+	}
+	return reflect.ValueOf(polyInput{
+		coeffs: coeffs,
+		n:      len(coeffs) + 1 + r.Intn(4),
+		k:      int64(r.Intn(40)),
+	})
+}
+
+func evalPoly(coeffs []int64, x int64) *big.Int {
+	sum := new(big.Int)
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		sum.Mul(sum, big.NewInt(x))
+		sum.Add(sum, big.NewInt(coeffs[i]))
+	}
+	return sum
+}
+
+func TestPolynomialExtrapolate(t *testing.T) {
+	if err := quick.Check(func(in polyInput) bool {
+		nums := make([]*big.Int, in.n)
+		for i := range nums {
+			nums[i] = evalPoly(in.coeffs, int64(i))
+		}
+		p := FitSequence(nums)
+		if !p.Exact {
+			return false
+		}
+		return p.Next(in.k).Cmp(evalPoly(in.coeffs, int64(in.n-1)+in.k)) == 0 &&
+			p.Prev(in.k).Cmp(evalPoly(in.coeffs, -in.k)) == 0
+	}, &quick.Config{
+		MaxCount: 2048,
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
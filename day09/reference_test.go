@@ -0,0 +1,72 @@
+package main
+
+import (
+	"math/big"
+	"math/rand"
+	"reflect"
+	"slices"
+	"testing"
+	"testing/quick"
+)
+
+type (
+	seqInput struct {
+		nums []int
+	}
+)
+
+// Generate creates a short sequence of small integers.
+func (seqInput) Generate(r *rand.Rand, size int) reflect.Value {
+	nums := make([]int, r.Intn(8)+1)
+	for i := range nums {
+		nums[i] = r.Intn(41) - 20
+	}
+	return reflect.ValueOf(seqInput{
+		nums: nums,
+	})
+}
+
+// findNextSeqRef recursively builds difference arrays to predict the next
+// value.
+func findNextSeqRef(nums []int) int {
+	numsAllZero := true
+	for _, i := range nums {
+		if i != 0 {
+			numsAllZero = false
+			break
+		}
+	}
+	if numsAllZero {
+		return 0
+	}
+	next := make([]int, len(nums)-1)
+	for i := 1; i < len(nums); i++ {
+		next[i-1] = nums[i] - nums[i-1]
+	}
+	nextDiff := findNextSeqRef(next)
+	return nums[len(nums)-1] + nextDiff
+}
+
+func TestFitSequenceRef(t *testing.T) {
+	if err := quick.CheckEqual(
+		func(in seqInput) [2]int64 {
+			nums := make([]*big.Int, 0, len(in.nums))
+			for _, i := range in.nums {
+				nums = append(nums, big.NewInt(int64(i)))
+			}
+			p := FitSequence(nums)
+			return [2]int64{p.Next(1).Int64(), p.Prev(1).Int64()}
+		},
+		func(in seqInput) [2]int64 {
+			next := findNextSeqRef(in.nums)
+			rev := slices.Clone(in.nums)
+			slices.Reverse(rev)
+			return [2]int64{int64(next), int64(findNextSeqRef(rev))}
+		},
+		&quick.Config{
+			MaxCount: 4096,
+		},
+	); err != nil {
+		t.Fatal(err)
+	}
+}
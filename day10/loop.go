@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bufio"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"log"
+	"os"
+)
+
+type (
+	// TileClass is the position of a tile relative to the loop.
+	TileClass uint8
+)
+
+const (
+	TileOutside TileClass = iota
+	TileLoop
+	TileInside
+)
+
+const loopCellSize = 3
+
+// tileConnects reports whether a pipe tile has an opening toward d.
+func tileConnects(tile byte, d Dir) bool {
+	transform, ok := tileDirMap[tile]
+	if !ok {
+		return false
+	}
+	// a pipe that can be left toward d
+	for _, out := range transform {
+		if out == d {
+			return true
+		}
+	}
+	return false
+}
+
+// classifyTiles marks every tile as on the loop, inside, or outside of it.
+// Scanning each row from the left, a tile is inside if the scan has crossed
+// the loop an odd number of times, where only loop tiles that open to the
+// north count as crossings, so that a run such as L--7 counts once and L--J
+// counts twice. It returns the classes and the number of inside tiles.
+func classifyTiles(grid [][]byte, onLoop [][]bool) ([][]TileClass, int) {
+	classes := make([][]TileClass, len(grid))
+	count := 0
+	for y, row := range grid {
+		classes[y] = make([]TileClass, len(row))
+		inside := false
+		for x, b := range row {
+			if onLoop[y][x] {
+				classes[y][x] = TileLoop
+				if tileConnects(b, DirNorth) {
+					inside = !inside
+				}
+				continue
+			}
+			if inside {
+				classes[y][x] = TileInside
+				count++
+			}
+		}
+	}
+	return classes, count
+}
+
+var boxDrawing = map[byte]string{
+	'|': "│",
+	'-': "─",
+	'L': "└",
+	'J': "┘",
+	'7': "┐",
+	'F': "┌",
+}
+
+// renderLoop prints the loop with box drawing characters, enclosed tiles as
+// solid blocks, and everything else as blank.
+func renderLoop(w io.Writer, grid [][]byte, classes [][]TileClass) {
+	bw := bufio.NewWriter(w)
+	for y, row := range grid {
+		for x, b := range row {
+			switch classes[y][x] {
+			case TileLoop:
+				bw.WriteString(boxDrawing[b])
+			case TileInside:
+				bw.WriteString("█")
+			default:
+				bw.WriteByte(' ')
+			}
+		}
+		bw.WriteByte('\n')
+	}
+	if err := bw.Flush(); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// writeLoopPNG draws each tile as a 3x3 cell with the loop as lines through
+// the cell centers and enclosed tiles filled in.
+func writeLoopPNG(name string, grid [][]byte, classes [][]TileClass) {
+	height := len(grid)
+	width := len(grid[0])
+	img := image.NewRGBA(image.Rect(0, 0, width*loopCellSize, height*loopCellSize))
+	outside := color.RGBA{R: 224, G: 224, B: 208, A: 255}
+	inside := color.RGBA{R: 64, G: 176, B: 96, A: 255}
+	pipe := color.RGBA{R: 32, G: 32, B: 96, A: 255}
+	for y, row := range grid {
+		for x, b := range row {
+			bg := outside
+			if classes[y][x] == TileInside {
+				bg = inside
+			}
+			for i := 0; i < loopCellSize; i++ {
+				for j := 0; j < loopCellSize; j++ {
+					img.Set(x*loopCellSize+j, y*loopCellSize+i, bg)
+				}
+			}
+			if classes[y][x] != TileLoop {
+				continue
+			}
+			cx := x*loopCellSize + loopCellSize/2
+			cy := y*loopCellSize + loopCellSize/2
+			img.Set(cx, cy, pipe)
+			if tileConnects(b, DirNorth) {
+				img.Set(cx, cy-1, pipe)
+			}
+			if tileConnects(b, DirEast) {
+				img.Set(cx+1, cy, pipe)
+			}
+			if tileConnects(b, DirSouth) {
+				img.Set(cx, cy+1, pipe)
+			}
+			if tileConnects(b, DirWest) {
+				img.Set(cx-1, cy, pipe)
+			}
+		}
+	}
+
+	file, err := os.Create(name)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			log.Fatalln(err)
+		}
+	}()
+	if err := png.Encode(file, img); err != nil {
+		log.Fatalln(err)
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func parseGrid(s string) ([][]byte, Coord) {
+	var grid [][]byte
+	start := Coord{x: -1, y: -1}
+	for _, line := range strings.Fields(s) {
+		k := []byte(line)
+		if x := bytes.IndexByte(k, 'S'); x >= 0 {
+			start = Coord{
+				x: x,
+				y: len(grid),
+			}
+		}
+		grid = append(grid, k)
+	}
+	return grid, start
+}
+
+func TestClassifyTiles(t *testing.T) {
+	for tcn, tc := range []struct {
+		marked string
+		count  int
+	}{
+		{
+			marked: `
+...........
+.S-------7.
+.|F-----7|.
+.||.....||.
+.||.....||.
+.|L-7.F-J|.
+.|II|.|II|.
+.L--J.L--J.
+...........
+`,
+			count: 4,
+		},
+		{
+			marked: `
+..........
+.S------7.
+.|F----7|.
+.||....||.
+.||....||.
+.|L-7F-J|.
+.|II||II|.
+.L--JL--J.
+..........
+`,
+			count: 4,
+		},
+		{
+			marked: `
+.F----7F7F7F7F-7....
+.|F--7||||||||FJ....
+.||.FJ||||||||L7....
+FJL7L7LJLJ||LJIL-7..
+L--J.L7IIILJS7F-7L7.
+....F-JIIF7FJ|L7L7L7
+....L7IF7||L7|IL7L7|
+.....|FJLJ|FJ|F7|.LJ
+....FJL-7.||.||||...
+....L---J.LJ.LJLJ...
+`,
+			count: 8,
+		},
+		{
+			marked: `
+FF7FSF7F7F7F7F7F---7
+L|LJ||||||||||||F--J
+FL-7LJLJ||||||LJL-77
+F--JF--7||LJLJIF7FJ-
+L---JF-JLJIIIIFJLJJ7
+|F|F-JF---7IIIL7L|7|
+|FFJF7L7F-JF7IIL---7
+7-L-JL7||F7|L7F-7F7|
+L.L7LFJ|||||FJL7||LJ
+L7JLJL-JLJLJL--JLJ.L
+`,
+			count: 10,
+		},
+	} {
+		tc := tc
+		t.Run("classify test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			grid, start := parseGrid(tc.marked)
+			for _, row := range grid {
+				for x, b := range row {
+					if b == 'I' {
+						row[x] = '.'
+					}
+				}
+			}
+			startTile, err := inferStartTile(grid, start)
+			if err != nil {
+				t.Fatal(err)
+			}
+			grid[start.y][start.x] = startTile
+			loop, ok := traceLoop(grid, start)
+			if !ok {
+				t.Fatal("Failed to trace loop")
+			}
+			classes, count := classifyTiles(grid, loop.OnLoop)
+			if count != tc.count {
+				t.Fatalf("Invalid inside count %d != %d", count, tc.count)
+			}
+			if v := loop.Path.InteriorPoints(); v != count {
+				t.Fatalf("Inside count %d disagrees with area %d", count, v)
+			}
+			marked, _ := parseGrid(tc.marked)
+			for y, row := range marked {
+				for x, b := range row {
+					if (b == 'I') != (classes[y][x] == TileInside) {
+						t.Fatalf("Invalid class %d at %d,%d", classes[y][x], x, y)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestInferStartTile(t *testing.T) {
+	for tcn, tc := range []struct {
+		grid string
+		tile byte
+		ok   bool
+	}{
+		{
+			grid: `
+.....
+.S-7.
+.|.|.
+.L-J.
+.....
+`,
+			tile: 'F',
+			ok:   true,
+		},
+		{
+			// the pipe west of the start leads into a dead end
+			grid: `
+..F7.
+-SJ|.
+.L-J.
+`,
+			tile: 'F',
+			ok:   true,
+		},
+		{
+			grid: `
+.....
+.-S-.
+.....
+`,
+			ok: false,
+		},
+		{
+			// two loops cross at the start, so both J and F close a loop
+			grid: `
+F-7..
+|.|..
+L-S-7
+..|.|
+..L-J
+`,
+			ok: false,
+		},
+	} {
+		tc := tc
+		t.Run("start test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			grid, start := parseGrid(tc.grid)
+			tile, err := inferStartTile(grid, start)
+			if !tc.ok {
+				if !errors.Is(err, errStartTile) {
+					t.Fatalf("Expected start tile error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tile != tc.tile {
+				t.Fatalf("Invalid start tile %c != %c", tile, tc.tile)
+			}
+			if grid[start.y][start.x] != 'S' {
+				t.Fatalf("Start tile was not restored")
+			}
+		})
+	}
+}
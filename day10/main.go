@@ -3,9 +3,13 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+
+	"github.com/xorkevin/advent2023/geom"
 )
 
 const (
@@ -13,6 +17,10 @@ const (
 )
 
 func main() {
+	render := flag.Bool("render", false, "print the loop with enclosed tiles highlighted")
+	pngName := flag.String("png", "", "write the loop with enclosed tiles highlighted to a png file")
+	flag.Parse()
+
 	file, err := os.Open(puzzleInput)
 	if err != nil {
 		log.Fatalln(err)
@@ -43,26 +51,99 @@ func main() {
 		log.Fatalln(err)
 	}
 
-	curPos, curDir, ok := getStartNeighbor(grid, start)
+	if start.x < 0 {
+		log.Fatalln("Missing start")
+	}
+	startTile, err := inferStartTile(grid, start)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	grid[start.y][start.x] = startTile
+
+	loop, ok := traceLoop(grid, start)
 	if !ok {
-		log.Fatalln("Missing start neighbor")
-	}
-	steps := 1
-	area := 0
-	switch curDir {
-	case DirNorth:
-	case DirEast:
-		area += curPos.y
-	case DirSouth:
-	case DirWest:
-		area -= curPos.y
-	}
-	for curPos != start {
-		transform, ok := tileDirMap[grid[curPos.y][curPos.x]]
-		if !ok {
-			log.Fatalln("Invalid pipe path")
+		log.Fatalln("Invalid pipe path")
+	}
+	if loop.Steps%2 != 0 {
+		log.Fatalln("Pipe path not aligned to grid")
+	}
+	fmt.Println("Part 1:", loop.Steps/2)
+	fmt.Println("Part 2:", loop.Path.InteriorPoints())
+
+	if !*render && *pngName == "" {
+		return
+	}
+	classes, _ := classifyTiles(grid, loop.OnLoop)
+	if *render {
+		renderLoop(os.Stdout, grid, classes)
+	}
+	if *pngName != "" {
+		writeLoopPNG(*pngName, grid, classes)
+	}
+}
+
+var errStartTile = errors.New("Invalid start tile")
+
+// inferStartTile finds the pipe shape under the start tile by substituting
+// every shape and keeping those that close a loop back into the start. It
+// is an error for no shape or for several shapes to close a loop.
+func inferStartTile(grid [][]byte, start Coord) (byte, error) {
+	orig := grid[start.y][start.x]
+	defer func() {
+		grid[start.y][start.x] = orig
+	}()
+	var shapes []byte
+	for _, i := range []byte(pipeTiles) {
+		grid[start.y][start.x] = i
+		if _, ok := traceLoop(grid, start); ok {
+			shapes = append(shapes, i)
 		}
-		curDir = transform[curDir]
+	}
+	switch len(shapes) {
+	case 0:
+		return 0, fmt.Errorf("%w: no pipe shape at %d,%d closes a loop", errStartTile, start.x, start.y)
+	case 1:
+		return shapes[0], nil
+	default:
+		return 0, fmt.Errorf("%w: pipe shapes %s at %d,%d all close a loop", errStartTile, shapes, start.x, start.y)
+	}
+}
+
+type (
+	// Loop is the pipe loop through the start tile.
+	Loop struct {
+		OnLoop [][]bool
+		Steps  int
+		// Path is the polygon through the centers of the tiles of the loop.
+		Path geom.Polygon
+	}
+)
+
+// traceLoop follows the pipes out of the start tile, recording the path as
+// it goes. It reports false if the path leaves
+// the grid, reaches a tile that does not connect, or does not reenter the
+// start tile through its other opening.
+func traceLoop(grid [][]byte, start Coord) (Loop, bool) {
+	transform, ok := tileDirMap[grid[start.y][start.x]]
+	if !ok {
+		return Loop{}, false
+	}
+	var startDir Dir = -1
+	for _, i := range transform {
+		if i >= 0 {
+			startDir = i
+			break
+		}
+	}
+	onLoop := make([][]bool, len(grid))
+	for n, i := range grid {
+		onLoop[n] = make([]bool, len(i))
+	}
+	curPos := start
+	curDir := startDir
+	steps := 0
+	path := geom.Polygon{Points: []geom.Point{{X: start.x, Y: start.y}}}
+	for {
 		switch curDir {
 		case DirNorth:
 			curPos.y--
@@ -73,32 +154,31 @@ func main() {
 		case DirWest:
 			curPos.x--
 		default:
-			log.Fatalln("Invalid pipe connection")
+			return Loop{}, false
 		}
+		if curPos.y < 0 || curPos.y >= len(grid) || curPos.x < 0 || curPos.x >= len(grid[curPos.y]) {
+			return Loop{}, false
+		}
+		onLoop[curPos.y][curPos.x] = true
 		steps++
-		switch curDir {
-		case DirNorth:
-		case DirEast:
-			area += curPos.y
-		case DirSouth:
-		case DirWest:
-			area -= curPos.y
+		path.Points = append(path.Points, geom.Point{X: curPos.x, Y: curPos.y})
+		transform, ok := tileDirMap[grid[curPos.y][curPos.x]]
+		if !ok {
+			return Loop{}, false
+		}
+		curDir = transform[curDir]
+		if curPos == start {
+			if curDir != startDir {
+				return Loop{}, false
+			}
+			break
 		}
 	}
-	if steps%2 != 0 {
-		log.Fatalln("Pipe path not aligned to grid")
-	}
-	halfSteps := steps / 2
-	fmt.Println("Part 1:", halfSteps)
-	area = abs(area)
-	fmt.Println("Part 2:", area-halfSteps+1)
-}
-
-func abs(a int) int {
-	if a < 0 {
-		return -a
-	}
-	return a
+	return Loop{
+		OnLoop: onLoop,
+		Steps:  steps,
+		Path:   path,
+	}, true
 }
 
 type (
@@ -116,6 +196,8 @@ const (
 	DirWest      = 3
 )
 
+const pipeTiles = "|-LJ7F"
+
 var tileDirMap = map[byte][4]Dir{
 	'|': {DirNorth, -1, DirSouth, -1},
 	'-': {-1, DirEast, -1, DirWest},
@@ -124,49 +206,3 @@ var tileDirMap = map[byte][4]Dir{
 	'7': {DirWest, DirSouth, -1, -1},
 	'F': {DirEast, -1, -1, DirSouth},
 }
-
-func getStartNeighbor(grid [][]byte, pos Coord) (Coord, Dir, bool) {
-	h := len(grid)
-	w := len(grid[0])
-	if pos.x > 0 {
-		// has left neighbor
-		switch grid[pos.y][pos.x-1] {
-		case '-', 'L', 'F':
-			return Coord{
-				x: pos.x - 1,
-				y: pos.y,
-			}, DirWest, true
-		}
-	}
-	if pos.y > 0 {
-		// has top neighbor
-		switch grid[pos.y-1][pos.x] {
-		case '|', '7', 'F':
-			return Coord{
-				x: pos.x,
-				y: pos.y - 1,
-			}, DirNorth, true
-		}
-	}
-	if pos.x+1 < w {
-		// has right neighbor
-		switch grid[pos.y][pos.x+1] {
-		case '-', 'J', '7':
-			return Coord{
-				x: pos.x + 1,
-				y: pos.y,
-			}, DirEast, true
-		}
-	}
-	if pos.y+1 < h {
-		// has bot neighbor
-		switch grid[pos.y+1][pos.x] {
-		case '|', 'L', 'J':
-			return Coord{
-				x: pos.x,
-				y: pos.y + 1,
-			}, DirSouth, true
-		}
-	}
-	return Coord{}, DirNorth, false
-}
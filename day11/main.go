@@ -2,9 +2,14 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"log"
+	"math"
+	"math/big"
 	"os"
+	"slices"
+	"strings"
 )
 
 const (
@@ -12,6 +17,9 @@ const (
 )
 
 func main() {
+	factorsFlag := flag.String("factors", "", "comma separated expansion factors to sum distances for")
+	flag.Parse()
+
 	file, err := os.Open(puzzleInput)
 	if err != nil {
 		log.Fatalln(err)
@@ -66,17 +74,20 @@ func main() {
 		}
 	}
 
-	sd := 0
-	se := 0
-	for n, i := range coords {
-		for _, j := range coords[n+1:] {
-			sd += manhattanDistance(i, j)
-			se += calcExpansion(emptyRows, i.y, j.y) + calcExpansion(emptyColumns, i.x, j.x)
+	sd, se := sumDistances(coords, emptyRows, emptyColumns)
+
+	if *factorsFlag == "" {
+		fmt.Println("Part 1:", totalDistance(sd, se, big.NewInt(2)))
+		fmt.Println("Part 2:", totalDistance(sd, se, big.NewInt(1000000)))
+		return
+	}
+	for _, i := range strings.Split(*factorsFlag, ",") {
+		factor, ok := new(big.Int).SetString(i, 10)
+		if !ok || factor.Sign() <= 0 {
+			log.Fatalln("Invalid factor", i)
 		}
+		fmt.Printf("Factor %s: %s\n", factor, totalDistance(sd, se, factor))
 	}
-
-	fmt.Println("Part 1:", sd+se)
-	fmt.Println("Part 2:", sd+se*999999)
 }
 
 type (
@@ -85,28 +96,55 @@ type (
 	}
 )
 
-func calcExpansion(emptyRows []int, a, b int) int {
-	if a > b {
-		a, b = b, a
-	}
-	left := 0
-	for left < len(emptyRows) && emptyRows[left] < a {
-		left++
+// sumDistances returns the sum of distances between every pair of galaxies
+// before expansion, and the number of empty rows and columns crossed by those
+// paths. Expanding by a factor f moves every galaxy by f-1 for each empty
+// line before it, so the distance between every pair grows by f-1 for each
+// empty line between them, and the sum for any factor is sd + (f-1)*se.
+func sumDistances(coords []Coord, emptyRows, emptyColumns []int) (int, int) {
+	xs := make([]int, 0, len(coords))
+	ys := make([]int, 0, len(coords))
+	for _, i := range coords {
+		xs = append(xs, i.x)
+		ys = append(ys, i.y)
 	}
-	right := len(emptyRows) - 1
-	for right >= 0 && emptyRows[right] > b {
-		right--
-	}
-	return right - left + 1
+	sdx, sex := sumAxisDistances(xs, emptyColumns)
+	sdy, sey := sumAxisDistances(ys, emptyRows)
+	return sdx + sdy, sex + sey
 }
 
-func abs(a int) int {
-	if a < 0 {
-		return -a
+// sumAxisDistances sorts the positions of galaxies along one axis and sums
+// the distance between every pair, and the number of sorted empty lines
+// between every pair. In sorted order the i-th of n positions is subtracted
+// by the n-1-i positions after it and subtracts the i positions before it,
+// so it contributes (2i-n+1) times its value to the sum, and the same holds
+// for the number of empty lines before it.
+func sumAxisDistances(pos []int, empty []int) (int, int) {
+	slices.Sort(pos)
+	n := len(pos)
+	sd := 0
+	se := 0
+	k := 0
+	for i, p := range pos {
+		for k < len(empty) && empty[k] < p {
+			k++
+		}
+		w := 2*i - n + 1
+		sd += w * p
+		se += w * k
 	}
-	return a
+	return sd, se
 }
 
-func manhattanDistance(a, b Coord) int {
-	return abs(a.x-b.x) + abs(a.y-b.y)
+// totalDistance returns sd + (factor-1)*se, falling back to big.Int
+// arithmetic when the result does not fit in an int.
+func totalDistance(sd, se int, factor *big.Int) *big.Int {
+	if factor.IsInt64() {
+		if f := factor.Int64() - 1; f >= 0 && (se == 0 || f <= int64((math.MaxInt-sd)/se)) {
+			return big.NewInt(int64(sd + int(f)*se))
+		}
+	}
+	res := new(big.Int).Sub(factor, big.NewInt(1))
+	res.Mul(res, big.NewInt(int64(se)))
+	return res.Add(res, big.NewInt(int64(sd)))
 }
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+)
+
+type (
+	imageInput struct {
+		grid   [][]byte
+		factor int
+	}
+)
+
+// Generate creates a small sparse image and an expansion factor.
+func (imageInput) Generate(r *rand.Rand, size int) reflect.Value {
+	h := r.Intn(12) + 1
+	w := r.Intn(12) + 1
+	grid := make([][]byte, h)
+	for y := range grid {
+		grid[y] = make([]byte, w)
+		for x := range grid[y] {
+			if r.Intn(6) == 0 {
+				grid[y][x] = '#'
+			} else {
+				grid[y][x] = '.'
+			}
+		}
+	}
+	return reflect.ValueOf(imageInput{
+		grid:   grid,
+		factor: r.Intn(5) + 2,
+	})
+}
+
+func parseImage(grid [][]byte) ([]Coord, []int, []int) {
+	var coords []Coord
+	var emptyRows []int
+	var emptyColumns []int
+	for y, row := range grid {
+		isEmpty := true
+		for x, i := range row {
+			if i == '#' {
+				isEmpty = false
+				coords = append(coords, Coord{
+					x: x,
+					y: y,
+				})
+			}
+		}
+		if isEmpty {
+			emptyRows = append(emptyRows, y)
+		}
+	}
+	for x := range grid[0] {
+		isEmpty := true
+		for _, row := range grid {
+			if row[x] == '#' {
+				isEmpty = false
+				break
+			}
+		}
+		if isEmpty {
+			emptyColumns = append(emptyColumns, x)
+		}
+	}
+	return coords, emptyRows, emptyColumns
+}
+
+// sumExpandedDistancesRef literally expands the image by duplicating every
+// empty row and column and then sums the distances between every pair of
+// galaxies.
+func sumExpandedDistancesRef(grid [][]byte, factor int) int {
+	var rows [][]byte
+	for _, row := range grid {
+		rows = append(rows, row)
+		if bytes.IndexByte(row, '#') < 0 {
+			for i := 1; i < factor; i++ {
+				rows = append(rows, row)
+			}
+		}
+	}
+	expanded := make([][]byte, len(rows))
+	for x := range grid[0] {
+		isEmpty := true
+		for _, row := range grid {
+			if row[x] == '#' {
+				isEmpty = false
+				break
+			}
+		}
+		for y, row := range rows {
+			expanded[y] = append(expanded[y], row[x])
+			if isEmpty {
+				for i := 1; i < factor; i++ {
+					expanded[y] = append(expanded[y], row[x])
+				}
+			}
+		}
+	}
+	var coords []Coord
+	for y, row := range expanded {
+		for x, i := range row {
+			if i == '#' {
+				coords = append(coords, Coord{
+					x: x,
+					y: y,
+				})
+			}
+		}
+	}
+	sum := 0
+	for _, i := range coords {
+		for _, j := range coords {
+			sum += manhattanDistance(i, j)
+		}
+	}
+	return sum / 2
+}
+
+func TestSumDistances(t *testing.T) {
+	if err := quick.CheckEqual(
+		func(in imageInput) int {
+			sd, se := sumDistances(parseImage(in.grid))
+			return sd + se*(in.factor-1)
+		},
+		func(in imageInput) int {
+			return sumExpandedDistancesRef(in.grid, in.factor)
+		},
+		&quick.Config{
+			MaxCount: 4096,
+		},
+	); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTotalDistance(t *testing.T) {
+	if err := quick.Check(func(sd, se uint32, factor uint64) bool {
+		f := new(big.Int).SetUint64(factor | 1)
+		exp := new(big.Int).Sub(f, big.NewInt(1))
+		exp.Mul(exp, big.NewInt(int64(se)))
+		exp.Add(exp, big.NewInt(int64(sd)))
+		return totalDistance(int(sd), int(se), f).Cmp(exp) == 0
+	}, &quick.Config{
+		MaxCount: 4096,
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+func manhattanDistance(a, b Coord) int {
+	return abs(a.x-b.x) + abs(a.y-b.y)
+}
@@ -3,8 +3,11 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"flag"
 	"fmt"
 	"log"
+	"math/big"
+	"math/rand"
 	"os"
 	"strconv"
 )
@@ -14,6 +17,16 @@ const (
 )
 
 func main() {
+	unfold := flag.Int("unfold", 5, "number of copies of each row for part 2")
+	rowNum := flag.Int("row", 0, "line number of a row to inspect instead of solving")
+	list := flag.Int("list", 10, "maximum number of arrangements of the inspected row to list")
+	samples := flag.Int("sample", 0, "number of uniformly random arrangements of the inspected row to print")
+	seed := flag.Int64("seed", 1, "random seed for sampling")
+	flag.Parse()
+	if *unfold < 1 {
+		log.Fatalln("Invalid unfold factor")
+	}
+
 	file, err := os.Open(puzzleInput)
 	if err != nil {
 		log.Fatalln(err)
@@ -24,11 +37,11 @@ func main() {
 		}
 	}()
 
-	sum := 0
-	sum2 := 0
+	sum := new(big.Int)
+	sum2 := new(big.Int)
 
 	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
+	for lineNum := 1; scanner.Scan(); lineNum++ {
 		a, b, ok := bytes.Cut(scanner.Bytes(), []byte{' '})
 		if !ok {
 			log.Fatalln("Invalid line")
@@ -41,105 +54,55 @@ func main() {
 			}
 			nums = append(nums, num)
 		}
-		cache := make([]int, len(a)*len(nums))
-		sum += getNumArrangements(a, nums, cache, len(a))
-		aa := make([]byte, 0, len(a)*5+5)
-		bb := make([]int, 0, len(nums)*5)
-		for i := 0; i < 5; i++ {
-			if len(aa) > 0 {
-				aa = append(aa, '?')
+		row := SpringRow{
+			Springs: bytes.Clone(a),
+			Groups:  nums,
+		}
+		if *rowNum != 0 {
+			if lineNum == *rowNum {
+				inspectRow(row.Unfold(*unfold), *list, *samples, rand.New(rand.NewSource(*seed)))
+				return
 			}
-			aa = append(aa, a...)
-			bb = append(bb, nums...)
+			continue
 		}
-		cache = make([]int, len(aa)*len(bb))
-		sum2 += getNumArrangements(aa, bb, cache, len(aa))
+		sum.Add(sum, NewArrangements(row).Count())
+		sum2.Add(sum2, NewArrangements(row.Unfold(*unfold)).Count())
 	}
 
 	if err := scanner.Err(); err != nil {
 		log.Fatalln(err)
 	}
 
-	fmt.Println("Part 1:", sum)
-	fmt.Println("Part 2:", sum2)
-}
-
-func getNumArrangements(b []byte, nums []int, cache []int, cacheRowWidth int) int {
-	if len(nums) == 0 {
-		if restNoGroup(b) {
-			return 1
-		} else {
-			return 0
-		}
-	}
-	if len(b) == 0 {
-		return 0
-	}
-
-	key := cacheRowWidth*(len(nums)-1) + len(b) - 1
-	if n := cache[key]; n > 0 {
-		return n - 1
+	if *rowNum != 0 {
+		log.Fatalln("Row not found")
 	}
 
-	first := b[0]
-	if first == '.' {
-		count := getNumArrangements(b[1:], nums, cache, cacheRowWidth)
-		cache[key] = count + 1
-		return count
-	}
-
-	firstNum := nums[0]
-	prefixMatches, isEnd := matchPrefix(b, firstNum)
-	if isEnd {
-		if len(nums) == 1 {
-			cache[key] = 2
-			return 1
-		}
-		cache[key] = 1
-		return 0
-	}
-
-	count := 0
-	if first == '?' {
-		count += getNumArrangements(b[1:], nums, cache, cacheRowWidth)
-	} else {
-		if !prefixMatches {
-			cache[key] = 1
-			return 0
-		}
-	}
-	if prefixMatches {
-		count += getNumArrangements(b[firstNum+1:], nums[1:], cache, cacheRowWidth)
-	}
-
-	cache[key] = count + 1
-
-	return count
+	fmt.Println("Part 1:", sum)
+	fmt.Println("Part 2:", sum2)
 }
 
-func restNoGroup(b []byte) bool {
-	for _, i := range b {
-		if i == '#' {
+func inspectRow(row SpringRow, list, samples int, r *rand.Rand) {
+	arr := NewArrangements(row)
+	fmt.Printf("%s %v\n", row.Springs, row.Groups)
+	fmt.Println("Arrangements:", arr.Count())
+	n := 0
+	arr.Each(func(s string) bool {
+		if n >= list {
+			fmt.Println("...")
 			return false
 		}
+		fmt.Println(s)
+		n++
+		return true
+	})
+	if samples > 0 {
+		fmt.Println("Samples:")
 	}
-	return true
-}
-
-func matchPrefix(b []byte, num int) (bool, bool) {
-	if len(b) < num {
-		return false, false
-	}
-	for i := 0; i < num; i++ {
-		if b[i] == '.' {
-			return false, false
+	for i := 0; i < samples; i++ {
+		s, ok := arr.Sample(r)
+		if !ok {
+			break
 		}
+		fmt.Println(s)
 	}
-	if len(b) == num {
-		return true, true
-	}
-	if b[num] == '#' {
-		return false, false
-	}
-	return true, false
 }
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"math/big"
+	"math/rand"
+	"reflect"
+	"slices"
+	"testing"
+	"testing/quick"
+)
+
+type (
+	springsInput struct {
+		row  []byte
+		nums []int
+	}
+)
+
+// Generate creates a short row of springs. The groups are usually those of
+// a random filling of the row, so that most rows have arrangements.
+func (springsInput) Generate(r *rand.Rand, size int) reflect.Value {
+	n := r.Intn(14) + 1
+	row := make([]byte, n)
+	filled := make([]byte, n)
+	for i := range row {
+		filled[i] = ".#"[r.Intn(2)]
+		if r.Intn(2) == 0 {
+			row[i] = '?'
+		} else {
+			row[i] = filled[i]
+		}
+	}
+	nums := springGroups(filled)
+	if r.Intn(4) == 0 {
+		nums = nil
+		for i := r.Intn(4); i > 0; i-- {
+			nums = append(nums, r.Intn(4)+1)
+		}
+	}
+	return reflect.ValueOf(springsInput{
+		row:  row,
+		nums: nums,
+	})
+}
+
+func springGroups(row []byte) []int {
+	var groups []int
+	count := 0
+	for _, i := range row {
+		if i == '#' {
+			count++
+		} else if count > 0 {
+			groups = append(groups, count)
+			count = 0
+		}
+	}
+	if count > 0 {
+		groups = append(groups, count)
+	}
+	return groups
+}
+
+// allArrangementsRef tries every assignment of the unknown springs and
+// returns those that match the groups, in the order the arrangements are
+// enumerated, operational before damaged.
+func allArrangementsRef(row []byte, nums []int) []string {
+	var unknown []int
+	for n, i := range row {
+		if i == '?' {
+			unknown = append(unknown, n)
+		}
+	}
+	candidate := slices.Clone(row)
+	var res []string
+	for mask := 0; mask < 1<<len(unknown); mask++ {
+		for n, i := range unknown {
+			// the first unknown spring is the most significant bit
+			if mask&(1<<(len(unknown)-1-n)) != 0 {
+				candidate[i] = '#'
+			} else {
+				candidate[i] = '.'
+			}
+		}
+		if slices.Equal(springGroups(candidate), nums) {
+			res = append(res, string(candidate))
+		}
+	}
+	return res
+}
+
+func TestArrangements(t *testing.T) {
+	if err := quick.CheckEqual(
+		func(in springsInput) []string {
+			arr := NewArrangements(SpringRow{
+				Springs: in.row,
+				Groups:  in.nums,
+			})
+			var res []string
+			arr.Each(func(s string) bool {
+				res = append(res, s)
+				return true
+			})
+			if arr.Count().Cmp(big.NewInt(int64(len(res)))) != 0 {
+				return nil
+			}
+			return res
+		},
+		func(in springsInput) []string {
+			return allArrangementsRef(in.row, in.nums)
+		},
+		&quick.Config{
+			MaxCount: 4096,
+		},
+	); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestArrangementsSample(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	if err := quick.Check(func(in springsInput) bool {
+		arr := NewArrangements(SpringRow{
+			Springs: in.row,
+			Groups:  in.nums,
+		})
+		all := allArrangementsRef(in.row, in.nums)
+		s, ok := arr.Sample(r)
+		if !ok {
+			return len(all) == 0
+		}
+		return slices.Contains(all, s)
+	}, &quick.Config{
+		MaxCount: 4096,
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestArrangementsSampleUniform checks that every arrangement of a row is
+// sampled about equally often.
+func TestArrangementsSampleUniform(t *testing.T) {
+	arr := NewArrangements(SpringRow{
+		Springs: []byte("?###????????"),
+		Groups:  []int{3, 2, 1},
+	})
+	const samples = 20000
+	r := rand.New(rand.NewSource(1))
+	counts := map[string]int{}
+	for i := 0; i < samples; i++ {
+		s, ok := arr.Sample(r)
+		if !ok {
+			t.Fatal("Expected an arrangement")
+		}
+		counts[s]++
+	}
+	// the example row has 10 arrangements
+	if len(counts) != 10 {
+		t.Fatalf("Invalid number of sampled arrangements %d", len(counts))
+	}
+	for k, v := range counts {
+		if v < samples/10*8/10 || v > samples/10*12/10 {
+			t.Fatalf("Arrangement %s sampled %d times out of %d", k, v, samples)
+		}
+	}
+}
+
+func TestArrangementsUnfold(t *testing.T) {
+	for _, tc := range []struct {
+		row    string
+		groups []int
+		unfold int
+		count  string
+	}{
+		{row: "???.###", groups: []int{1, 1, 3}, unfold: 5, count: "1"},
+		{row: ".??..??...?##.", groups: []int{1, 1, 3}, unfold: 5, count: "16384"},
+		{row: "?###????????", groups: []int{3, 2, 1}, unfold: 5, count: "506250"},
+		// far more arrangements than fit in an int
+		{row: "???", groups: []int{1}, unfold: 40, count: "114556848244965165743109806892471"},
+	} {
+		arr := NewArrangements(SpringRow{
+			Springs: []byte(tc.row),
+			Groups:  tc.groups,
+		}.Unfold(tc.unfold))
+		if v := arr.Count().String(); v != tc.count {
+			t.Fatalf("Invalid count %s != %s for %s", v, tc.count, tc.row)
+		}
+		if isBig := len(tc.count) > 18; arr.IsBig() != isBig {
+			t.Fatalf("Invalid big table %t != %t for %s", arr.IsBig(), isBig, tc.row)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"strings"
+)
+
+type (
+	// SpringRow is a row of springs, where each spring is operational '.',
+	// damaged '#', or unknown '?', and the sizes of each contiguous group of
+	// damaged springs in order.
+	SpringRow struct {
+		Springs []byte
+		Groups  []int
+	}
+
+	// Arrangements is the table of the number of ways to arrange every
+	// suffix of the springs of a row with every suffix of its groups. Counts
+	// are kept as ints unless any count overflows, in which case the whole
+	// table is kept as big.Ints.
+	Arrangements struct {
+		row    SpringRow
+		stride int
+		// dots[i] is the number of operational springs before i
+		dots []int
+		// hashes[i] is the number of damaged springs before i
+		hashes []int
+		small  []int
+		big    []*big.Int
+	}
+)
+
+// Unfold returns the row repeated k times, with the springs of each copy
+// separated by an unknown spring.
+func (r SpringRow) Unfold(k int) SpringRow {
+	res := SpringRow{
+		Springs: make([]byte, 0, (len(r.Springs)+1)*k),
+		Groups:  make([]int, 0, len(r.Groups)*k),
+	}
+	for i := 0; i < k; i++ {
+		if i > 0 {
+			res.Springs = append(res.Springs, '?')
+		}
+		res.Springs = append(res.Springs, r.Springs...)
+		res.Groups = append(res.Groups, r.Groups...)
+	}
+	return res
+}
+
+// NewArrangements fills the table of arrangements of a row from the end of
+// the row backwards.
+func NewArrangements(row SpringRow) *Arrangements {
+	n := len(row.Springs)
+	a := &Arrangements{
+		row:    row,
+		stride: len(row.Groups) + 1,
+		dots:   make([]int, n+1),
+		hashes: make([]int, n+1),
+	}
+	for i, c := range row.Springs {
+		a.dots[i+1] = a.dots[i]
+		a.hashes[i+1] = a.hashes[i]
+		switch c {
+		case '.':
+			a.dots[i+1]++
+		case '#':
+			a.hashes[i+1]++
+		}
+	}
+	if !a.fillSmall() {
+		a.small = nil
+		a.fillBig()
+	}
+	return a
+}
+
+func (a *Arrangements) key(i, j int) int {
+	return i*a.stride + j
+}
+
+// next returns the cells that the arrangements of springs[i:] with
+// groups[j:] are made of: those that start with an operational spring, and
+// those that start with the group j. A cell of -1 has no arrangements. When
+// every group is placed, base reports whether the rest of the row can be
+// operational.
+func (a *Arrangements) next(i, j int) (dot, group int, base bool) {
+	n := len(a.row.Springs)
+	if j == len(a.row.Groups) {
+		return -1, -1, a.hashes[n] == a.hashes[i]
+	}
+	dot, group = -1, -1
+	if i == n {
+		return dot, group, false
+	}
+	c := a.row.Springs[i]
+	if c != '#' {
+		dot = a.key(i+1, j)
+	}
+	if g := a.row.Groups[j]; c != '.' && i+g <= n && a.dots[i+g] == a.dots[i] {
+		if i+g == n {
+			group = a.key(n, j+1)
+		} else if a.row.Springs[i+g] != '#' {
+			group = a.key(i+g+1, j+1)
+		}
+	}
+	return dot, group, false
+}
+
+// fillSmall fills the table with ints, reporting false if a count
+// overflows.
+func (a *Arrangements) fillSmall() bool {
+	a.small = make([]int, (len(a.row.Springs)+1)*a.stride)
+	for i := len(a.row.Springs); i >= 0; i-- {
+		for j := len(a.row.Groups); j >= 0; j-- {
+			dot, group, base := a.next(i, j)
+			count := 0
+			if base {
+				count = 1
+			}
+			if dot >= 0 {
+				count = a.small[dot]
+			}
+			if group >= 0 {
+				if count > math.MaxInt-a.small[group] {
+					return false
+				}
+				count += a.small[group]
+			}
+			a.small[a.key(i, j)] = count
+		}
+	}
+	return true
+}
+
+func (a *Arrangements) fillBig() {
+	a.big = make([]*big.Int, (len(a.row.Springs)+1)*a.stride)
+	for i := len(a.row.Springs); i >= 0; i-- {
+		for j := len(a.row.Groups); j >= 0; j-- {
+			dot, group, base := a.next(i, j)
+			count := new(big.Int)
+			if base {
+				count.SetInt64(1)
+			}
+			if dot >= 0 {
+				count.Set(a.big[dot])
+			}
+			if group >= 0 {
+				count.Add(count, a.big[group])
+			}
+			a.big[a.key(i, j)] = count
+		}
+	}
+}
+
+func (a *Arrangements) at(k int) *big.Int {
+	if a.big != nil {
+		return a.big[k]
+	}
+	return big.NewInt(int64(a.small[k]))
+}
+
+// IsBig reports whether the counts overflowed an int.
+func (a *Arrangements) IsBig() bool {
+	return a.big != nil
+}
+
+// Count returns the number of arrangements of the row.
+func (a *Arrangements) Count() *big.Int {
+	return a.at(0)
+}
+
+// Each calls fn with every arrangement of the row, as the springs with
+// every unknown spring filled in, until fn returns false. Only cells with
+// arrangements are visited, so every step toward the next arrangement is
+// productive.
+func (a *Arrangements) Each(fn func(s string) bool) {
+	a.each(0, 0, make([]byte, 0, len(a.row.Springs)), fn)
+}
+
+func (a *Arrangements) each(i, j int, prefix []byte, fn func(s string) bool) bool {
+	dot, group, base := a.next(i, j)
+	if base {
+		for range a.row.Springs[i:] {
+			prefix = append(prefix, '.')
+		}
+		return fn(string(prefix))
+	}
+	if dot >= 0 && a.at(dot).Sign() > 0 {
+		if !a.each(i+1, j, append(prefix, '.'), fn) {
+			return false
+		}
+	}
+	if group >= 0 && a.at(group).Sign() > 0 {
+		next := prefix
+		for k := a.row.Groups[j]; k > 0; k-- {
+			next = append(next, '#')
+		}
+		if len(next) < len(a.row.Springs) {
+			next = append(next, '.')
+		}
+		if !a.each(len(next), j+1, next, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// Sample returns an arrangement of the row chosen uniformly at random, or
+// false if the row has none. At each spring it starts a group or leaves the
+// spring operational with probability proportional to the number of
+// arrangements that follow from each choice.
+func (a *Arrangements) Sample(r *rand.Rand) (string, bool) {
+	if a.Count().Sign() == 0 {
+		return "", false
+	}
+	var b strings.Builder
+	i, j := 0, 0
+	for {
+		dot, group, base := a.next(i, j)
+		if base {
+			b.WriteString(strings.Repeat(".", len(a.row.Springs)-i))
+			return b.String(), true
+		}
+		takeGroup := dot < 0
+		if dot >= 0 && group >= 0 {
+			total := a.at(a.key(i, j))
+			pick := new(big.Int).Rand(r, total)
+			takeGroup = pick.Cmp(a.at(dot)) >= 0
+		}
+		if !takeGroup {
+			b.WriteByte('.')
+			i++
+			continue
+		}
+		g := a.row.Groups[j]
+		b.WriteString(strings.Repeat("#", g))
+		i += g
+		if i < len(a.row.Springs) {
+			b.WriteByte('.')
+			i++
+		}
+		j++
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -12,6 +13,9 @@ const (
 )
 
 func main() {
+	smudgeCount := flag.Int("smudges", -1, "list every mirror with exactly this many smudges instead of solving")
+	flag.Parse()
+
 	file, err := os.Open(puzzleInput)
 	if err != nil {
 		log.Fatalln(err)
@@ -22,8 +26,7 @@ func main() {
 		}
 	}()
 
-	sum := 0
-	sum2 := 0
+	var patterns []Pattern
 
 	var grid [][]byte
 
@@ -35,12 +38,11 @@ func main() {
 			continue
 		}
 
-		s, s2, ok := findReflections(grid)
+		p, ok := NewPattern(grid)
 		if !ok {
-			log.Fatalln("No mirror")
+			log.Fatalln("Invalid pattern")
 		}
-		sum += s
-		sum2 += s2
+		patterns = append(patterns, p)
 		grid = nil
 	}
 
@@ -48,124 +50,51 @@ func main() {
 		log.Fatalln(err)
 	}
 
-	s, s2, ok := findReflections(grid)
-	if !ok {
-		log.Fatalln("No mirror")
-	}
-	sum += s
-	sum2 += s2
-
-	fmt.Println("Part 1:", sum)
-	fmt.Println("Part 2:", sum2)
-}
-
-func findReflections(grid [][]byte) (int, int, bool) {
-	tgrid := transpose(grid)
-	s, eh, ev, ok := findReflection(grid, tgrid)
+	p, ok := NewPattern(grid)
 	if !ok {
-		return 0, 0, false
+		log.Fatalln("Invalid pattern")
 	}
-	s2, ok := findSmudgeReflection(grid, tgrid, eh, ev)
-	if !ok {
-		return 0, 0, false
-	}
-	return s, s2, true
-}
-
-func findReflection(grid, transpose [][]byte) (int, int, int, bool) {
-	if hm := findMirror(grid); hm > 0 {
-		return hm * 100, hm, -1, true
-	}
-	if vm := findMirror(transpose); vm > 0 {
-		return vm, -1, vm, true
-	}
-	return 0, -1, -1, false
-}
-
-func findSmudgeReflection(grid, transpose [][]byte, eh, ev int) (int, bool) {
-	if hm := findSmudge(grid, eh); hm > 0 {
-		return hm * 100, true
-	}
-	if vm := findSmudge(transpose, ev); vm > 0 {
-		return vm, true
-	}
-	return 0, false
-}
+	patterns = append(patterns, p)
 
-func findSmudge(grid [][]byte, except int) int {
-	for i := 1; i < len(grid); i++ {
-		if i == except {
-			continue
-		}
-		if isAlmostMirroredAt(grid, i) {
-			return i
-		}
-	}
-	return -1
-}
-
-func isAlmostMirroredAt(grid [][]byte, r int) bool {
-	height := len(grid)
-	lim := min(height-r, r)
-	hasDiff := false
-	for i := 0; i < lim; i++ {
-		a := grid[r-i-1]
-		b := grid[r+i]
-		if string(a) != string(b) {
-			if !isEditDistance1(a, b) {
-				return false
+	if *smudgeCount >= 0 {
+		sum := 0
+		for n, i := range patterns {
+			for _, m := range i.Mirrors(*smudgeCount) {
+				writeMirror(n+1, m)
+				sum += m.Score()
 			}
-			if hasDiff {
-				return false
-			}
-			hasDiff = true
 		}
+		fmt.Println("Sum:", sum)
+		return
 	}
-	return hasDiff
-}
 
-func isEditDistance1(a, b []byte) bool {
-	hasDiff := false
-	for i := range a {
-		if a[i] != b[i] {
-			if hasDiff {
-				return false
-			}
-			hasDiff = true
-		}
+	sum := 0
+	sum2 := 0
+	for _, i := range patterns {
+		sum += summarize(i, 0)
+		sum2 += summarize(i, 1)
 	}
-	return hasDiff
-}
 
-func findMirror(grid [][]byte) int {
-	for i := 1; i < len(grid); i++ {
-		if isMirroredAt(grid, i) {
-			return i
-		}
-	}
-	return -1
+	fmt.Println("Part 1:", sum)
+	fmt.Println("Part 2:", sum2)
 }
 
-func isMirroredAt(grid [][]byte, r int) bool {
-	height := len(grid)
-	lim := min(height-r, r)
-	for i := 0; i < lim; i++ {
-		if string(grid[r-i-1]) != string(grid[r+i]) {
-			return false
-		}
+// summarize returns the score of the first mirror with k smudges.
+func summarize(p Pattern, k int) int {
+	mirrors := p.Mirrors(k)
+	if len(mirrors) == 0 {
+		log.Fatalln("No mirror")
 	}
-	return true
+	return mirrors[0].Score()
 }
 
-func transpose(grid [][]byte) [][]byte {
-	height := len(grid)
-	width := len(grid[0])
-	res := make([][]byte, width)
-	for i := range res {
-		res[i] = make([]byte, height)
-		for j := range res[i] {
-			res[i][j] = grid[j][i]
-		}
+func writeMirror(pattern int, m Mirror) {
+	if m.Vertical {
+		fmt.Printf("Pattern %d: vertical mirror right of column %d\n", pattern, m.Pos)
+	} else {
+		fmt.Printf("Pattern %d: horizontal mirror below row %d\n", pattern, m.Pos)
+	}
+	for _, i := range m.Smudges {
+		fmt.Printf("  flip row %d column %d or row %d column %d\n", i.A.y+1, i.A.x+1, i.B.y+1, i.B.x+1)
 	}
-	return res
 }
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"math/bits"
+)
+
+type (
+	// Pattern is a grid of ash '.' and rocks '#' with each row and column
+	// packed into the bits of as many words as it needs, where bit i%64 of
+	// word i/64 is set if the cell at index i is a rock.
+	Pattern struct {
+		Rows   [][]uint64
+		Cols   [][]uint64
+		Width  int
+		Height int
+	}
+
+	Coord struct {
+		x, y int
+	}
+
+	// Smudge is a pair of cells that a mirror reflects onto each other but
+	// that differ. Flipping either cell removes the difference.
+	Smudge struct {
+		A, B Coord
+	}
+
+	// Mirror is a line of reflection. A horizontal mirror lies below row Pos
+	// and a vertical mirror lies to the right of column Pos, counting from 1.
+	Mirror struct {
+		Vertical bool
+		Pos      int
+		Smudges  []Smudge
+	}
+)
+
+func packedLines(n, length int) [][]uint64 {
+	words := (length + 63) / 64
+	buf := make([]uint64, n*words)
+	lines := make([][]uint64, n)
+	for i := range lines {
+		lines[i] = buf[i*words : (i+1)*words]
+	}
+	return lines
+}
+
+// NewPattern packs a grid, reporting false if it is empty or its rows differ
+// in length.
+func NewPattern(grid [][]byte) (Pattern, bool) {
+	if len(grid) == 0 || len(grid[0]) == 0 {
+		return Pattern{}, false
+	}
+	p := Pattern{
+		Rows:   packedLines(len(grid), len(grid[0])),
+		Cols:   packedLines(len(grid[0]), len(grid)),
+		Width:  len(grid[0]),
+		Height: len(grid),
+	}
+	for y, row := range grid {
+		if len(row) != p.Width {
+			return Pattern{}, false
+		}
+		for x, c := range row {
+			if c == '#' {
+				p.Rows[y][x/64] |= 1 << (x % 64)
+				p.Cols[x][y/64] |= 1 << (y % 64)
+			}
+		}
+	}
+	return p, true
+}
+
+// Score returns the summary value of a mirror.
+func (m Mirror) Score() int {
+	if m.Vertical {
+		return m.Pos
+	}
+	return m.Pos * 100
+}
+
+// Mirrors returns every horizontal and then every vertical mirror whose
+// reflected halves differ in exactly k cells.
+func (p Pattern) Mirrors(k int) []Mirror {
+	var res []Mirror
+	for _, r := range mirrorLines(p.Rows, k) {
+		res = append(res, Mirror{
+			Vertical: false,
+			Pos:      r,
+			Smudges:  smudges(p.Rows, r, false),
+		})
+	}
+	for _, c := range mirrorLines(p.Cols, k) {
+		res = append(res, Mirror{
+			Vertical: true,
+			Pos:      c,
+			Smudges:  smudges(p.Cols, c, true),
+		})
+	}
+	return res
+}
+
+// mirrorLines returns every line r between lines r-1 and r for which the
+// reflected lines differ in exactly k bits, giving up on a line once it
+// differs in more.
+func mirrorLines(lines [][]uint64, k int) []int {
+	var res []int
+	for r := 1; r < len(lines); r++ {
+		diff := 0
+		for i := 0; i < min(r, len(lines)-r) && diff <= k; i++ {
+			a, b := lines[r-i-1], lines[r+i]
+			for w := range a {
+				diff += bits.OnesCount64(a[w] ^ b[w])
+			}
+		}
+		if diff == k {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// smudges lists the differing cells of the lines reflected about r. If
+// transposed, the lines are columns.
+func smudges(lines [][]uint64, r int, transposed bool) []Smudge {
+	var res []Smudge
+	for i := 0; i < min(r, len(lines)-r); i++ {
+		a, b := r-i-1, r+i
+		for w := range lines[a] {
+			for d := lines[a][w] ^ lines[b][w]; d != 0; d &= d - 1 {
+				j := w*64 + bits.TrailingZeros64(d)
+				s := Smudge{
+					A: Coord{x: j, y: a},
+					B: Coord{x: j, y: b},
+				}
+				if transposed {
+					s.A.x, s.A.y = s.A.y, s.A.x
+					s.B.x, s.B.y = s.B.y, s.B.x
+				}
+				res = append(res, s)
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"testing/quick"
+)
+
+const examplePatterns = `
+#.##..##.
+..#.##.#.
+##......#
+##......#
+..#.##.#.
+..##..##.
+#.#.##.#.
+
+#...##..#
+#....#..#
+..##..###
+#####.##.
+#####.##.
+..##..###
+#....#..#
+`
+
+func parsePatterns(t *testing.T, s string) []Pattern {
+	t.Helper()
+	var patterns []Pattern
+	for _, block := range strings.Split(strings.TrimSpace(s), "\n\n") {
+		var grid [][]byte
+		for _, line := range strings.Fields(block) {
+			grid = append(grid, []byte(line))
+		}
+		p, ok := NewPattern(grid)
+		if !ok {
+			t.Fatal("Invalid pattern")
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns
+}
+
+func TestMirrorsExample(t *testing.T) {
+	patterns := parsePatterns(t, examplePatterns)
+	for _, tc := range []struct {
+		k     int
+		score int
+	}{
+		{k: 0, score: 405},
+		{k: 1, score: 400},
+	} {
+		sum := 0
+		for _, i := range patterns {
+			sum += summarize(i, tc.k)
+		}
+		if sum != tc.score {
+			t.Fatalf("Invalid score %d != %d for %d smudges", sum, tc.score, tc.k)
+		}
+	}
+	// the smudge in the first pattern is in the top left corner, reflected
+	// about the line below row 3 onto row 6
+	m := patterns[0].Mirrors(1)[0]
+	exp := []Smudge{{A: Coord{x: 0, y: 0}, B: Coord{x: 0, y: 5}}}
+	if m.Vertical || m.Pos != 3 || !reflect.DeepEqual(m.Smudges, exp) {
+		t.Fatalf("Invalid smudge mirror %+v", m)
+	}
+}
+
+type (
+	patternInput struct {
+		grid [][]byte
+		k    int
+	}
+)
+
+// Generate creates a small pattern that is mostly mirrored about a random
+// line, so that mirrors with few smudges are common.
+func (patternInput) Generate(r *rand.Rand, size int) reflect.Value {
+	h := r.Intn(8) + 1
+	w := r.Intn(8) + 1
+	// some patterns span more than one word per row or column
+	if r.Intn(8) == 0 {
+		h = r.Intn(150) + 1
+	}
+	if r.Intn(8) == 0 {
+		w = r.Intn(150) + 1
+	}
+	grid := make([][]byte, h)
+	mid := r.Intn(h)
+	for y := range grid {
+		grid[y] = make([]byte, w)
+		for x := range grid[y] {
+			if m := 2*mid - 1 - y; y >= mid && m >= 0 && r.Intn(8) != 0 {
+				grid[y][x] = grid[m][x]
+			} else {
+				grid[y][x] = ".#"[r.Intn(2)]
+			}
+		}
+	}
+	return reflect.ValueOf(patternInput{
+		grid: grid,
+		k:    r.Intn(4),
+	})
+}
+
+// mirrorsRef compares reflected cells one at a time and returns the score of
+// every mirror with exactly k differing cells.
+func mirrorsRef(grid [][]byte, k int) []int {
+	var res []int
+	h := len(grid)
+	w := len(grid[0])
+	for r := 1; r < h; r++ {
+		diff := 0
+		for i := 0; i < min(r, h-r); i++ {
+			for x := 0; x < w; x++ {
+				if grid[r-i-1][x] != grid[r+i][x] {
+					diff++
+				}
+			}
+		}
+		if diff == k {
+			res = append(res, r*100)
+		}
+	}
+	for c := 1; c < w; c++ {
+		diff := 0
+		for i := 0; i < min(c, w-c); i++ {
+			for y := 0; y < h; y++ {
+				if grid[y][c-i-1] != grid[y][c+i] {
+					diff++
+				}
+			}
+		}
+		if diff == k {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+func TestMirrors(t *testing.T) {
+	if err := quick.Check(func(in patternInput) bool {
+		p, ok := NewPattern(in.grid)
+		if !ok {
+			return false
+		}
+		var scores []int
+		for _, m := range p.Mirrors(in.k) {
+			scores = append(scores, m.Score())
+			if len(m.Smudges) != in.k {
+				return false
+			}
+			// flipping one cell of every smudge makes the mirror exact
+			flipped := make([][]byte, len(in.grid))
+			for n, i := range in.grid {
+				flipped[n] = []byte(string(i))
+			}
+			for _, s := range m.Smudges {
+				flipped[s.A.y][s.A.x] = flipped[s.B.y][s.B.x]
+			}
+			if !slices.Contains(mirrorsRef(flipped, 0), m.Score()) {
+				return false
+			}
+		}
+		return reflect.DeepEqual(scores, mirrorsRef(in.grid, in.k))
+	}, &quick.Config{
+		MaxCount: 4096,
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
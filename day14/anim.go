@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+type (
+	// tiltFrame is the grid after one tilt, in the orientation of the input.
+	tiltFrame struct {
+		Grid  [][]byte
+		Cycle int
+		// Tilt is the direction of the tilt, or 0 for the initial grid.
+		Tilt byte
+		Load int
+		// Changed marks the rows whose load differs from the previous frame.
+		Changed []bool
+		// PeriodStart is set on the frames of the first repeated cycle.
+		PeriodStart bool
+	}
+)
+
+const (
+	animCellSize = 4
+	glyphWidth   = 3
+	glyphHeight  = 5
+	bannerScale  = 2
+	bannerHeight = (glyphHeight + 2) * bannerScale
+)
+
+var tiltOrder = [4]byte{'N', 'W', 'S', 'E'}
+
+// rotateCCW returns the grid rotated counterclockwise, undoing the clockwise
+// rotation of dropRocks.
+func rotateCCW(grid [][]byte) [][]byte {
+	height := len(grid)
+	width := len(grid[0])
+	res := make([][]byte, width)
+	for i := range res {
+		res[i] = make([]byte, height)
+		for j := range res[i] {
+			res[i][j] = grid[j][width-i-1]
+		}
+	}
+	return res
+}
+
+func cloneGrid(grid [][]byte) [][]byte {
+	res := make([][]byte, len(grid))
+	for n, i := range grid {
+		res[n] = append([]byte(nil), i...)
+	}
+	return res
+}
+
+func rowLoads(grid [][]byte) []int {
+	height := len(grid)
+	loads := make([]int, height)
+	for r, i := range grid {
+		for _, j := range i {
+			if j == 'O' {
+				loads[r] += height - r
+			}
+		}
+	}
+	return loads
+}
+
+// recordFrames runs spin cycles on a copy of the grid until a state repeats,
+// recording the grid after every tilt. Each tilt of a cycle leaves the grid
+// rotated clockwise one more time than the last, so the kth tilt is rotated
+// back counterclockwise k times.
+func recordFrames(input [][]byte) []tiltFrame {
+	height := len(input)
+	width := len(input[0])
+	grid := cloneGrid(input)
+	other := make([][]byte, width)
+	for i := range other {
+		other[i] = make([]byte, height)
+	}
+
+	frames := []tiltFrame{{
+		Grid: cloneGrid(grid),
+		Load: scoreRocks(grid),
+	}}
+	prevLoads := rowLoads(grid)
+	add := func(cycle int, tilt int, src [][]byte) {
+		g := cloneGrid(src)
+		for i := 0; i < tilt; i++ {
+			g = rotateCCW(g)
+		}
+		loads := rowLoads(g)
+		changed := make([]bool, len(loads))
+		for n := range loads {
+			changed[n] = loads[n] != prevLoads[n]
+		}
+		prevLoads = loads
+		frames = append(frames, tiltFrame{
+			Grid:    g,
+			Cycle:   cycle,
+			Tilt:    tiltOrder[tilt],
+			Load:    scoreRocks(g),
+			Changed: changed,
+		})
+	}
+
+	cache := map[string]int{}
+	for i := 0; ; i++ {
+		dropRocks(grid, other)
+		add(i+1, 0, grid)
+		dropRocks(other, grid)
+		add(i+1, 1, other)
+		dropRocks(grid, other)
+		add(i+1, 2, grid)
+		dropRocks(other, grid)
+		add(i+1, 3, other)
+		s := getState(grid)
+		if n, ok := cache[s]; ok {
+			// frames of cycle n+1 are the first of the period
+			for k := 1 + n*4; k < 1+(n+1)*4; k++ {
+				frames[k].PeriodStart = true
+			}
+			return frames
+		}
+		cache[s] = i
+	}
+}
+
+func (f tiltFrame) caption() string {
+	if f.Tilt == 0 {
+		return "START LOAD " + strconv.Itoa(f.Load)
+	}
+	s := "CYCLE " + strconv.Itoa(f.Cycle) + " " + string(f.Tilt) + " LOAD " + strconv.Itoa(f.Load)
+	if f.PeriodStart {
+		s += " REPEAT"
+	}
+	return s
+}
+
+// playFrames redraws each frame in the terminal, highlighting the rows whose
+// load changed.
+func playFrames(w io.Writer, frames []tiltFrame, delay time.Duration) {
+	bw := bufio.NewWriter(w)
+	for _, f := range frames {
+		// move to the top left and clear the screen
+		bw.WriteString("\x1b[H\x1b[2J")
+		fmt.Fprintln(bw, f.caption())
+		for r, row := range f.Grid {
+			if f.Changed != nil && f.Changed[r] {
+				bw.WriteString("\x1b[7m")
+				bw.Write(row)
+				bw.WriteString("\x1b[0m")
+			} else {
+				bw.Write(row)
+			}
+			bw.WriteByte('\n')
+		}
+		if err := bw.Flush(); err != nil {
+			log.Fatalln(err)
+		}
+		time.Sleep(delay)
+	}
+}
+
+var animPalette = color.Palette{
+	color.RGBA{R: 240, G: 236, B: 220, A: 255}, // empty
+	color.RGBA{R: 255, G: 224, B: 128, A: 255}, // empty in a changed row
+	color.RGBA{R: 64, G: 64, B: 72, A: 255},    // cube rock
+	color.RGBA{R: 208, G: 96, B: 32, A: 255},   // round rock
+	color.RGBA{R: 32, G: 32, B: 48, A: 255},    // banner
+	color.RGBA{R: 160, G: 32, B: 32, A: 255},   // banner at the period start
+	color.RGBA{R: 255, G: 255, B: 255, A: 255}, // text
+}
+
+const (
+	animEmpty uint8 = iota
+	animChanged
+	animCube
+	animRound
+	animBanner
+	animRepeat
+	animText
+)
+
+// glyphs is a 3x5 pixel font for the captions.
+var glyphs = map[rune][glyphHeight]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", ".##", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", ".#.", ".#.", ".#."},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'A': {".#.", "#.#", "###", "#.#", "#.#"},
+	'C': {"###", "#..", "#..", "#..", "###"},
+	'D': {"##.", "#.#", "#.#", "#.#", "##."},
+	'E': {"###", "#..", "##.", "#..", "###"},
+	'L': {"#..", "#..", "#..", "#..", "###"},
+	'N': {"#.#", "###", "###", "###", "#.#"},
+	'O': {"###", "#.#", "#.#", "#.#", "###"},
+	'P': {"###", "#.#", "###", "#..", "#.."},
+	'R': {"##.", "#.#", "##.", "#.#", "#.#"},
+	'S': {"###", "#..", "###", "..#", "###"},
+	'T': {"###", ".#.", ".#.", ".#.", ".#."},
+	'W': {"#.#", "#.#", "###", "###", "#.#"},
+	'Y': {"#.#", "#.#", ".#.", ".#.", ".#."},
+}
+
+func drawText(img *image.Paletted, x, y int, s string) {
+	for _, c := range s {
+		g, ok := glyphs[c]
+		if ok {
+			for gy, row := range g {
+				for gx, b := range row {
+					if b != '#' {
+						continue
+					}
+					for i := 0; i < bannerScale; i++ {
+						for j := 0; j < bannerScale; j++ {
+							img.SetColorIndex(x+gx*bannerScale+j, y+gy*bannerScale+i, animText)
+						}
+					}
+				}
+			}
+		}
+		x += (glyphWidth + 1) * bannerScale
+	}
+}
+
+// writeAnimationGIF writes every frame with its caption in a banner above
+// the grid, and pauses on the last frame.
+func writeAnimationGIF(name string, frames []tiltFrame, delay time.Duration) {
+	height := len(frames[0].Grid)
+	width := len(frames[0].Grid[0])
+	rect := image.Rect(0, 0, width*animCellSize, height*animCellSize+bannerHeight)
+	anim := &gif.GIF{}
+	for _, f := range frames {
+		img := image.NewPaletted(rect, animPalette)
+		banner := animBanner
+		if f.PeriodStart {
+			banner = animRepeat
+		}
+		for y := 0; y < bannerHeight; y++ {
+			for x := 0; x < rect.Dx(); x++ {
+				img.SetColorIndex(x, y, banner)
+			}
+		}
+		drawText(img, bannerScale, bannerScale, f.caption())
+		for r, row := range f.Grid {
+			for c, b := range row {
+				idx := animEmpty
+				switch b {
+				case '#':
+					idx = animCube
+				case 'O':
+					idx = animRound
+				default:
+					if f.Changed != nil && f.Changed[r] {
+						idx = animChanged
+					}
+				}
+				for i := 0; i < animCellSize; i++ {
+					for j := 0; j < animCellSize; j++ {
+						img.SetColorIndex(c*animCellSize+j, bannerHeight+r*animCellSize+i, idx)
+					}
+				}
+			}
+		}
+		anim.Image = append(anim.Image, img)
+		anim.Delay = append(anim.Delay, int(delay/(10*time.Millisecond)))
+	}
+	anim.Delay[len(anim.Delay)-1] *= 10
+
+	file, err := os.Create(name)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			log.Fatalln(err)
+		}
+	}()
+	if err := gif.EncodeAll(file, anim); err != nil {
+		log.Fatalln(err)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const examplePlatform = `
+O....#....
+O.OO#....#
+.....##...
+OO.#O....O
+.O.....O#.
+O.#..O.#.#
+..O..#O..O
+.......O..
+#....###..
+#OO..#....
+`
+
+const exampleOneCycle = `
+.....#....
+....#...O#
+...OO##...
+.OO#......
+.....OOO#.
+.O#...O#.#
+....O#....
+......OOOO
+#...O###..
+#..OO#....
+`
+
+func parseGrid(s string) [][]byte {
+	var grid [][]byte
+	for _, i := range strings.Fields(s) {
+		grid = append(grid, []byte(i))
+	}
+	return grid
+}
+
+func TestRecordFrames(t *testing.T) {
+	input := parseGrid(examplePlatform)
+	frames := recordFrames(input)
+	if v := string(input[0]); v != "O....#...." {
+		t.Fatalf("Invalid input modified %s", v)
+	}
+	if v := (len(frames) - 1) % 4; v != 0 {
+		t.Fatalf("Invalid frame count %d", len(frames))
+	}
+	for tcn, tc := range []struct {
+		frame int
+		cycle int
+		tilt  byte
+		load  int
+		grid  string
+	}{
+		{frame: 0, cycle: 0, tilt: 0, load: 104, grid: examplePlatform},
+		{frame: 1, cycle: 1, tilt: 'N', load: 136},
+		{frame: 2, cycle: 1, tilt: 'W', load: 136},
+		{frame: 3, cycle: 1, tilt: 'S', load: 87},
+		{frame: 4, cycle: 1, tilt: 'E', load: 87, grid: exampleOneCycle},
+		{frame: 5, cycle: 2, tilt: 'N', load: 129},
+	} {
+		tc := tc
+		t.Run("frame test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			f := frames[tc.frame]
+			if f.Cycle != tc.cycle || f.Tilt != tc.tilt {
+				t.Fatalf("Invalid output %d %c != %d %c", f.Cycle, f.Tilt, tc.cycle, tc.tilt)
+			}
+			if f.Load != tc.load {
+				t.Fatalf("Invalid load %d != %d", f.Load, tc.load)
+			}
+			if tc.grid == "" {
+				return
+			}
+			for n, i := range parseGrid(tc.grid) {
+				if v := string(f.Grid[n]); v != string(i) {
+					t.Fatalf("Invalid row %d %s != %s", n, v, i)
+				}
+			}
+		})
+	}
+}
+
+func TestRecordFramesLoads(t *testing.T) {
+	frames := recordFrames(parseGrid(examplePlatform))
+	periodStarts := 0
+	for _, i := range frames {
+		if v := scoreRocks(i.Grid); i.Load != v {
+			t.Fatalf("Invalid load %d != %d", i.Load, v)
+		}
+		if i.PeriodStart {
+			periodStarts++
+		}
+	}
+	if periodStarts != 4 {
+		t.Fatalf("Invalid output %d != %d", periodStarts, 4)
+	}
+}
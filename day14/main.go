@@ -3,9 +3,11 @@ package main
 import (
 	"bufio"
 	"crypto/sha256"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"time"
 )
 
 const (
@@ -13,6 +15,11 @@ const (
 )
 
 func main() {
+	animate := flag.String("animate", "", "write the tilts of every spin cycle until the first repeat to a gif file")
+	play := flag.Bool("play", false, "play the tilts of every spin cycle until the first repeat in the terminal")
+	delay := flag.Duration("delay", 100*time.Millisecond, "delay between frames of the animation")
+	flag.Parse()
+
 	file, err := os.Open(puzzleInput)
 	if err != nil {
 		log.Fatalln(err)
@@ -34,35 +41,24 @@ func main() {
 		log.Fatalln(err)
 	}
 
-	height := len(grid)
-	width := len(grid[0])
-	other := make([][]byte, width)
-	for i := range other {
-		other[i] = make([]byte, height)
+	if *animate != "" || *play {
+		frames := recordFrames(grid)
+		if *animate != "" {
+			writeAnimationGIF(*animate, frames, *delay)
+		}
+		if *play {
+			playFrames(os.Stdout, frames, *delay)
+		}
+		return
 	}
 
-	dropRocks(grid, other)
-
-	fmt.Println("Part 1:", scoreRocks(grid))
+	p := NewPlatform(grid)
+	p.Tilt('N')
+	fmt.Println("Part 1:", p.Load())
 
-	remaining := 0
 	const p2Iterations = 1000000000
-	cache := map[string]int{}
-	for i := 0; i < p2Iterations; i++ {
-		cycle(grid, other)
-		s := getState(grid)
-		if n, ok := cache[s]; ok {
-			p := i - n
-			remaining = (p2Iterations - i - 1) % p
-			break
-		}
-		cache[s] = i
-	}
-	for i := 0; i < remaining; i++ {
-		cycle(grid, other)
-	}
-
-	fmt.Println("Part 2:", scoreRocks(grid))
+	p.Spin(p2Iterations)
+	fmt.Println("Part 2:", p.Load())
 }
 
 func getState(grid [][]byte) string {
@@ -113,14 +109,3 @@ func dropRock(grid [][]byte, other [][]byte, r, c int) {
 	other[c][height-r-1] = '.'
 	other[c][height-rest-1] = 'O'
 }
-
-func cycle(grid [][]byte, other [][]byte) {
-	// west
-	dropRocks(grid, other)
-	// south
-	dropRocks(other, grid)
-	// east
-	dropRocks(grid, other)
-	// north
-	dropRocks(other, grid)
-}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+	"strconv"
+	"testing"
+)
+
+// cycleRef runs a spin cycle on an unrotated grid. Each dropRocks tilts its
+// grid north in place and writes it rotated clockwise into the other, so that
+// the next north tilt is the next direction of the cycle, and after four
+// rotations the result is back in grid unrotated.
+func cycleRef(grid [][]byte, other [][]byte) {
+	// north
+	dropRocks(grid, other)
+	// west
+	dropRocks(other, grid)
+	// south
+	dropRocks(grid, other)
+	// east
+	dropRocks(other, grid)
+}
+
+// spinRef tilts the grid north as in part 1 and then runs n cycles. The
+// first tilt does not change the result, since each cycle starts by tilting
+// north.
+func spinRef(grid [][]byte, n int) {
+	height := len(grid)
+	width := len(grid[0])
+	other := make([][]byte, width)
+	for i := range other {
+		other[i] = make([]byte, height)
+	}
+
+	// part 1 north tilt, whose rotated copy in other is overwritten by the
+	// first cycle
+	dropRocks(grid, other)
+
+	remaining := 0
+	cache := map[string]int{}
+	for i := 0; i < n; i++ {
+		cycleRef(grid, other)
+		s := getState(grid)
+		if k, ok := cache[s]; ok {
+			p := i - k
+			remaining = (n - i - 1) % p
+			break
+		}
+		cache[s] = i
+	}
+	for i := 0; i < remaining; i++ {
+		cycleRef(grid, other)
+	}
+}
+
+func readPlatform(tb testing.TB) [][]byte {
+	tb.Helper()
+	b, err := os.ReadFile(puzzleInput)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return bytes.Split(bytes.TrimSpace(b), []byte("\n"))
+}
+
+func randPlatform(r *rand.Rand) [][]byte {
+	height := 1 + r.Intn(12)
+	width := 1 + r.Intn(12)
+	grid := make([][]byte, height)
+	for i := range grid {
+		grid[i] = make([]byte, width)
+		for j := range grid[i] {
+			grid[i][j] = ".#OO"[r.Intn(4)]
+		}
+	}
+	return grid
+}
+
+func checkPlatform(t *testing.T, grid [][]byte) {
+	t.Helper()
+	p := NewPlatform(grid)
+	for _, f := range recordFrames(grid) {
+		if f.Tilt != 0 {
+			p.Tilt(f.Tilt)
+		}
+		g := p.Grid()
+		for n, i := range f.Grid {
+			if !bytes.Equal(g[n], i) {
+				t.Fatalf("Invalid cycle %d tilt %c row %d %s != %s", f.Cycle, f.Tilt, n, g[n], i)
+			}
+		}
+		if v := p.Load(); v != f.Load {
+			t.Fatalf("Invalid cycle %d tilt %c load %d != %d", f.Cycle, f.Tilt, v, f.Load)
+		}
+	}
+}
+
+func TestPlatform(t *testing.T) {
+	r := rand.New(rand.NewSource(14))
+	for tcn, tc := range []struct {
+		grids func(t *testing.T) [][][]byte
+	}{
+		{
+			grids: func(t *testing.T) [][][]byte {
+				return [][][]byte{parseGrid(examplePlatform)}
+			},
+		},
+		{
+			grids: func(t *testing.T) [][][]byte {
+				return [][][]byte{readPlatform(t)}
+			},
+		},
+		{
+			grids: func(t *testing.T) [][][]byte {
+				grids := make([][][]byte, 200)
+				for i := range grids {
+					grids[i] = randPlatform(r)
+				}
+				return grids
+			},
+		},
+	} {
+		tc := tc
+		t.Run("platform test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			for _, i := range tc.grids(t) {
+				checkPlatform(t, i)
+			}
+		})
+	}
+}
+
+func TestPlatformSpin(t *testing.T) {
+	r := rand.New(rand.NewSource(14))
+	for tcn, tc := range []struct {
+		grid [][]byte
+		n    int
+		exp  int
+	}{
+		{
+			grid: parseGrid(examplePlatform),
+			n:    1000000000,
+			exp:  64,
+		},
+		{
+			grid: parseGrid(examplePlatform),
+			n:    1,
+			exp:  87,
+		},
+	} {
+		tc := tc
+		t.Run("spin test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			p := NewPlatform(tc.grid)
+			p.Spin(tc.n)
+			if v := p.Load(); v != tc.exp {
+				t.Fatalf("Invalid output %d != %d", v, tc.exp)
+			}
+		})
+	}
+
+	for i := 0; i < 200; i++ {
+		grid := randPlatform(r)
+		n := 1 + r.Intn(100)
+		t.Run("spin ref test case "+strconv.Itoa(i), func(t *testing.T) {
+			p := NewPlatform(grid)
+			p.Spin(n)
+			spinRef(grid, n)
+			if v, exp := p.Load(), scoreRocks(grid); v != exp {
+				t.Fatalf("Invalid output %d != %d", v, exp)
+			}
+		})
+	}
+}
+
+func BenchmarkCycleGrid(b *testing.B) {
+	grid := readPlatform(b)
+	other := make([][]byte, len(grid[0]))
+	for i := range other {
+		other[i] = make([]byte, len(grid))
+	}
+	dropRocks(grid, other)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cycleRef(grid, other)
+	}
+}
+
+func BenchmarkCyclePlatform(b *testing.B) {
+	p := NewPlatform(readPlatform(b))
+	p.Tilt('N')
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Cycle()
+	}
+}
+
+func BenchmarkSpinGrid(b *testing.B) {
+	input := readPlatform(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		spinRef(cloneGrid(input), 1000000000)
+	}
+}
+
+func BenchmarkSpinPlatform(b *testing.B) {
+	input := readPlatform(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p := NewPlatform(input)
+		p.Tilt('N')
+		p.Spin(1000000000)
+	}
+}
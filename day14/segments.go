@@ -0,0 +1,264 @@
+package main
+
+import (
+	"encoding/binary"
+)
+
+type (
+	// segment is a run of cells in a row or column between cube rocks or the
+	// edges of the platform, covering cells [start, end) of the line.
+	segment struct {
+		line       int
+		start, end int
+	}
+
+	// segmentAxis is every segment of the rows or of the columns, with the
+	// segment that each cell lies in.
+	segmentAxis struct {
+		segs []segment
+		// at is the index of the segment of each cell by row and column, or -1
+		// for a cube rock.
+		at []int
+	}
+
+	// Platform stores only the number of round rocks in each segment of the
+	// rows or of the columns, which after a tilt are packed against one end
+	// of their segment. A tilt moves the counts to the segments of the other
+	// axis in one pass over the rocks.
+	Platform struct {
+		width, height int
+		rows, cols    segmentAxis
+		// vertical is set when counts are of column segments
+		vertical bool
+		// high is set when rocks are packed against the end of their
+		// segments, south or east
+		high   bool
+		counts []int
+		// loose is the cells of the rocks of the untilted grid, which are not
+		// packed, and is nil after the first tilt
+		loose []int
+	}
+)
+
+func newSegmentAxis(grid [][]byte, vertical bool) segmentAxis {
+	height := len(grid)
+	width := len(grid[0])
+	lines, length := height, width
+	if vertical {
+		lines, length = width, height
+	}
+	a := segmentAxis{
+		at: make([]int, width*height),
+	}
+	for l := 0; l < lines; l++ {
+		start := 0
+		for i := 0; i <= length; i++ {
+			cell := l*width + i
+			if vertical {
+				cell = i*width + l
+			}
+			if i < length && grid[cell/width][cell%width] != '#' {
+				a.at[cell] = len(a.segs)
+				continue
+			}
+			if i < length {
+				a.at[cell] = -1
+			}
+			if start < i {
+				a.segs = append(a.segs, segment{
+					line:  l,
+					start: start,
+					end:   i,
+				})
+			}
+			start = i + 1
+		}
+	}
+	return a
+}
+
+// NewPlatform splits the rows and columns of a grid into segments.
+func NewPlatform(grid [][]byte) *Platform {
+	height := len(grid)
+	width := len(grid[0])
+	p := &Platform{
+		width:  width,
+		height: height,
+		rows:   newSegmentAxis(grid, false),
+		cols:   newSegmentAxis(grid, true),
+	}
+	p.loose = []int{}
+	for r, i := range grid {
+		for c, j := range i {
+			if j == 'O' {
+				p.loose = append(p.loose, r*width+c)
+			}
+		}
+	}
+	return p
+}
+
+func (p *Platform) axis(vertical bool) *segmentAxis {
+	if vertical {
+		return &p.cols
+	}
+	return &p.rows
+}
+
+// cell returns the cell at index i of a segment.
+func (p *Platform) cell(s segment, vertical bool, i int) int {
+	if vertical {
+		return i*p.width + s.line
+	}
+	return s.line*p.width + i
+}
+
+// eachRock calls fn with the cell of every round rock.
+func (p *Platform) eachRock(fn func(cell int)) {
+	if p.loose != nil {
+		for _, i := range p.loose {
+			fn(i)
+		}
+		return
+	}
+	for n, s := range p.axis(p.vertical).segs {
+		k := p.counts[n]
+		start := s.start
+		if p.high {
+			start = s.end - k
+		}
+		for i := start; i < start+k; i++ {
+			fn(p.cell(s, p.vertical, i))
+		}
+	}
+}
+
+// Tilt rolls every round rock toward 'N', 'W', 'S', or 'E'. A tilt along
+// the axis of the counts only moves the rocks to the other end of their
+// segments.
+func (p *Platform) Tilt(d byte) {
+	vertical := d == 'N' || d == 'S'
+	high := d == 'S' || d == 'E'
+	if p.loose == nil && vertical == p.vertical {
+		p.high = high
+		return
+	}
+	a := p.axis(vertical)
+	counts := make([]int, len(a.segs))
+	if p.loose != nil {
+		for _, i := range p.loose {
+			counts[a.at[i]]++
+		}
+	} else {
+		// same walk as eachRock without the call per rock
+		stride := 1
+		if p.vertical {
+			stride = p.width
+		}
+		for n, s := range p.axis(p.vertical).segs {
+			k := p.counts[n]
+			start := s.start
+			if p.high {
+				start = s.end - k
+			}
+			cell := p.cell(s, p.vertical, start)
+			for i := 0; i < k; i++ {
+				counts[a.at[cell]]++
+				cell += stride
+			}
+		}
+	}
+	p.counts = counts
+	p.vertical = vertical
+	p.high = high
+	p.loose = nil
+}
+
+// Cycle tilts north, west, south, and then east.
+func (p *Platform) Cycle() {
+	for _, i := range tiltOrder {
+		p.Tilt(i)
+	}
+}
+
+// Spin runs n cycles, skipping ahead once the platform returns to a state
+// it has been in before.
+func (p *Platform) Spin(n int) {
+	seen := map[string]int{}
+	for i := 0; i < n; i++ {
+		p.Cycle()
+		s := p.State()
+		if k, ok := seen[s]; ok {
+			remaining := (n - i - 1) % (i - k)
+			for j := 0; j < remaining; j++ {
+				p.Cycle()
+			}
+			return
+		}
+		seen[s] = i
+	}
+}
+
+// State returns a key that is equal for equal arrangements of rocks packed
+// in the same direction.
+func (p *Platform) State() string {
+	b := make([]byte, 0, len(p.counts)+1)
+	flags := byte(0)
+	if p.vertical {
+		flags |= 1
+	}
+	if p.high {
+		flags |= 2
+	}
+	b = append(b, flags)
+	for _, i := range p.counts {
+		b = binary.AppendUvarint(b, uint64(i))
+	}
+	return string(b)
+}
+
+// Load returns the total load on the north support beams.
+func (p *Platform) Load() int {
+	sum := 0
+	if p.loose != nil {
+		p.eachRock(func(cell int) {
+			sum += p.height - cell/p.width
+		})
+		return sum
+	}
+	if !p.vertical {
+		for n, s := range p.rows.segs {
+			sum += p.counts[n] * (p.height - s.line)
+		}
+		return sum
+	}
+	for n, s := range p.cols.segs {
+		k := p.counts[n]
+		start := s.start
+		if p.high {
+			start = s.end - k
+		}
+		// rows start through start+k-1
+		sum += k*(p.height-start) - k*(k-1)/2
+	}
+	return sum
+}
+
+// Grid returns the platform as rows of '.', '#', and 'O'.
+func (p *Platform) Grid() [][]byte {
+	grid := make([][]byte, p.height)
+	for r := range grid {
+		grid[r] = make([]byte, p.width)
+		for c := range grid[r] {
+			if p.rows.at[r*p.width+c] < 0 {
+				grid[r][c] = '#'
+			} else {
+				grid[r][c] = '.'
+			}
+		}
+	}
+	p.eachRock(func(cell int) {
+		grid[cell/p.width][cell%p.width] = 'O'
+	})
+	return grid
+}
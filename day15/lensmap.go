@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+)
+
+const numBoxes = 256
+
+type (
+	Lens struct {
+		Label string
+		Focal int
+	}
+
+	lensNode struct {
+		Lens
+		box        int
+		prev, next *lensNode
+	}
+
+	// lensBox is a doubly linked list of the lenses in a box in the order
+	// they were inserted.
+	lensBox struct {
+		head, tail *lensNode
+		size       int
+	}
+
+	// LensMap is the HASHMAP of the puzzle, an ordered map from labels to
+	// focal lengths. Each label is stored in the box given by its hash, and
+	// each box keeps its lenses in insertion order, where replacing the focal
+	// length of a lens keeps its slot.
+	LensMap struct {
+		boxes [numBoxes]lensBox
+		index map[string]*lensNode
+	}
+
+	// Op is a step of the initialization sequence, which either removes the
+	// lens with a label, or sets the focal length of the lens with a label.
+	Op struct {
+		Label  string
+		Remove bool
+		Focal  int
+	}
+)
+
+func NewLensMap() *LensMap {
+	return &LensMap{
+		index: map[string]*lensNode{},
+	}
+}
+
+// Len returns the number of lenses.
+func (m *LensMap) Len() int {
+	return len(m.index)
+}
+
+// Get returns the focal length of the lens with a label.
+func (m *LensMap) Get(label string) (int, bool) {
+	n, ok := m.index[label]
+	if !ok {
+		return 0, false
+	}
+	return n.Focal, true
+}
+
+// Set replaces the focal length of the lens with a label, or adds the lens
+// behind the other lenses of its box.
+func (m *LensMap) Set(label string, focal int) {
+	if n, ok := m.index[label]; ok {
+		n.Focal = focal
+		return
+	}
+	n := &lensNode{
+		Lens: Lens{
+			Label: label,
+			Focal: focal,
+		},
+		box: hashWord([]byte(label)),
+	}
+	b := &m.boxes[n.box]
+	if b.tail == nil {
+		b.head = n
+	} else {
+		b.tail.next = n
+		n.prev = b.tail
+	}
+	b.tail = n
+	b.size++
+	m.index[label] = n
+}
+
+// Delete removes the lens with a label, moving the lenses behind it forward,
+// and reports whether it was present.
+func (m *LensMap) Delete(label string) bool {
+	n, ok := m.index[label]
+	if !ok {
+		return false
+	}
+	b := &m.boxes[n.box]
+	if n.prev == nil {
+		b.head = n.next
+	} else {
+		n.prev.next = n.next
+	}
+	if n.next == nil {
+		b.tail = n.prev
+	} else {
+		n.next.prev = n.prev
+	}
+	b.size--
+	delete(m.index, label)
+	return true
+}
+
+// Box returns the lenses of a box from front to back.
+func (m *LensMap) Box(box int) []Lens {
+	b := m.boxes[box]
+	res := make([]Lens, 0, b.size)
+	for n := b.head; n != nil; n = n.next {
+		res = append(res, n.Lens)
+	}
+	return res
+}
+
+// Each calls fn with every lens from the first box to the last and from front
+// to back within a box, along with the box and the slot of the lens counting
+// from 0, until fn returns false.
+func (m *LensMap) Each(fn func(box, slot int, l Lens) bool) {
+	for box, b := range m.boxes {
+		slot := 0
+		for n := b.head; n != nil; n = n.next {
+			if !fn(box, slot, n.Lens) {
+				return
+			}
+			slot++
+		}
+	}
+}
+
+// FocusingPower returns the sum of the focusing power of every lens, the
+// product of one plus its box, its slot counting from 1, and its focal
+// length.
+func (m *LensMap) FocusingPower() int {
+	sum := 0
+	m.Each(func(box, slot int, l Lens) bool {
+		sum += (box + 1) * (slot + 1) * l.Focal
+		return true
+	})
+	return sum
+}
+
+// Apply runs a step of the initialization sequence.
+func (m *LensMap) Apply(op Op) {
+	if op.Remove {
+		m.Delete(op.Label)
+		return
+	}
+	m.Set(op.Label, op.Focal)
+}
+
+// WriteBoxes prints every nonempty box as in the puzzle, such as
+// "Box 0: [rn 1] [cm 2]".
+func (m *LensMap) WriteBoxes(w io.Writer) error {
+	for box, b := range m.boxes {
+		if b.head == nil {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "Box %d:", box); err != nil {
+			return err
+		}
+		for n := b.head; n != nil; n = n.next {
+			if _, err := fmt.Fprintf(w, " [%s %d]", n.Label, n.Focal); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var errOp = errors.New("Malformed op")
+
+// ParseOp parses a step such as "rn=1" or "cm-".
+func ParseOp(v []byte) (Op, error) {
+	if r, ok := bytes.CutSuffix(v, []byte{'-'}); ok {
+		return Op{
+			Label:  string(r),
+			Remove: true,
+		}, nil
+	}
+	if r, b, ok := bytes.Cut(v, []byte{'='}); ok {
+		num, err := strconv.Atoi(string(b))
+		if err != nil {
+			return Op{}, fmt.Errorf("%w %q: %w", errOp, v, err)
+		}
+		return Op{
+			Label: string(r),
+			Focal: num,
+		}, nil
+	}
+	return Op{}, fmt.Errorf("%w %q", errOp, v)
+}
+
+func (op Op) String() string {
+	if op.Remove {
+		return op.Label + "-"
+	}
+	return op.Label + "=" + strconv.Itoa(op.Focal)
+}
+
+// Replay applies each step of ops to an empty map, calling fn with the map
+// after each step until fn returns false, and returns the map.
+func Replay(ops []Op, fn func(step int, op Op, m *LensMap) bool) *LensMap {
+	m := NewLensMap()
+	for n, i := range ops {
+		m.Apply(i)
+		if fn != nil && !fn(n, i, m) {
+			break
+		}
+	}
+	return m
+}
+
+// writeReplay prints the boxes after every step as the puzzle does.
+func writeReplay(w io.Writer, ops []Op) {
+	bw := bufio.NewWriter(w)
+	Replay(ops, func(step int, op Op, m *LensMap) bool {
+		fmt.Fprintf(bw, "After %q:\n", op.String())
+		if err := m.WriteBoxes(bw); err != nil {
+			log.Fatalln(err)
+		}
+		bw.WriteString("\n")
+		return true
+	})
+	if err := bw.Flush(); err != nil {
+		log.Fatalln(err)
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const exampleSequence = "rn=1,cm-,qp=3,cm=2,qp-,pc=4,ot=9,ab=5,pc-,pc=6,ot=7"
+
+func parseExampleOps(t *testing.T) []Op {
+	t.Helper()
+	var ops []Op
+	for _, i := range strings.Split(exampleSequence, ",") {
+		op, err := ParseOp([]byte(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+func TestHashWord(t *testing.T) {
+	for tcn, tc := range []struct {
+		words string
+		exp   int
+	}{
+		{words: "HASH", exp: 52},
+		{words: "rn=1", exp: 30},
+		{words: "cm-", exp: 253},
+		{words: exampleSequence, exp: 1320},
+	} {
+		tc := tc
+		t.Run("hash test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			sum := 0
+			for _, i := range strings.Split(tc.words, ",") {
+				sum += hashWord([]byte(i))
+			}
+			if sum != tc.exp {
+				t.Fatalf("Invalid output %d != %d", sum, tc.exp)
+			}
+		})
+	}
+}
+
+func TestReplay(t *testing.T) {
+	ops := parseExampleOps(t)
+	for tcn, tc := range []struct {
+		op    string
+		boxes string
+	}{
+		{op: "rn=1", boxes: "Box 0: [rn 1]\n"},
+		{op: "cm-", boxes: "Box 0: [rn 1]\n"},
+		{op: "qp=3", boxes: "Box 0: [rn 1]\nBox 1: [qp 3]\n"},
+		{op: "cm=2", boxes: "Box 0: [rn 1] [cm 2]\nBox 1: [qp 3]\n"},
+		{op: "qp-", boxes: "Box 0: [rn 1] [cm 2]\n"},
+		{op: "pc=4", boxes: "Box 0: [rn 1] [cm 2]\nBox 3: [pc 4]\n"},
+		{op: "ot=9", boxes: "Box 0: [rn 1] [cm 2]\nBox 3: [pc 4] [ot 9]\n"},
+		{op: "ab=5", boxes: "Box 0: [rn 1] [cm 2]\nBox 3: [pc 4] [ot 9] [ab 5]\n"},
+		{op: "pc-", boxes: "Box 0: [rn 1] [cm 2]\nBox 3: [ot 9] [ab 5]\n"},
+		{op: "pc=6", boxes: "Box 0: [rn 1] [cm 2]\nBox 3: [ot 9] [ab 5] [pc 6]\n"},
+		{op: "ot=7", boxes: "Box 0: [rn 1] [cm 2]\nBox 3: [ot 7] [ab 5] [pc 6]\n"},
+	} {
+		tc := tc
+		tcn := tcn
+		t.Run("replay test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			var b bytes.Buffer
+			Replay(ops, func(step int, op Op, m *LensMap) bool {
+				if step < tcn {
+					return true
+				}
+				if v := op.String(); v != tc.op {
+					t.Fatalf("Invalid op %s != %s", v, tc.op)
+				}
+				if err := m.WriteBoxes(&b); err != nil {
+					t.Fatal(err)
+				}
+				return false
+			})
+			if v := b.String(); v != tc.boxes {
+				t.Fatalf("Invalid output\n%s\n!=\n%s", v, tc.boxes)
+			}
+		})
+	}
+}
+
+func TestReplayLen(t *testing.T) {
+	ops := parseExampleOps(t)
+	for tcn, tc := range []struct {
+		steps int
+		exp   int
+	}{
+		{steps: 1, exp: 1},
+		{steps: 2, exp: 1},
+		{steps: 3, exp: 2},
+		{steps: 4, exp: 3},
+		{steps: 5, exp: 2},
+		{steps: len(ops), exp: 5},
+	} {
+		tc := tc
+		t.Run("replay len test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			m := Replay(ops, func(step int, op Op, m *LensMap) bool {
+				return step+1 < tc.steps
+			})
+			if v := m.Len(); v != tc.exp {
+				t.Fatalf("Invalid output %d != %d", v, tc.exp)
+			}
+		})
+	}
+}
+
+func TestLensMap(t *testing.T) {
+	for tcn, tc := range []struct {
+		ops   []Op
+		power int
+		box3  []Lens
+		label string
+		focal int
+		ok    bool
+	}{
+		{
+			ops:   nil,
+			power: 145,
+			box3:  []Lens{{Label: "ot", Focal: 7}, {Label: "ab", Focal: 5}, {Label: "pc", Focal: 6}},
+			label: "rn",
+			focal: 1,
+			ok:    true,
+		},
+		{
+			ops:   nil,
+			power: 145,
+			box3:  []Lens{{Label: "ot", Focal: 7}, {Label: "ab", Focal: 5}, {Label: "pc", Focal: 6}},
+			label: "qp",
+			focal: 0,
+			ok:    false,
+		},
+		{
+			ops:   nil,
+			power: 145,
+			box3:  []Lens{{Label: "ot", Focal: 7}, {Label: "ab", Focal: 5}, {Label: "pc", Focal: 6}},
+			label: "cm",
+			focal: 2,
+			ok:    true,
+		},
+		{
+			ops:   nil,
+			power: 145,
+			box3:  []Lens{{Label: "ot", Focal: 7}, {Label: "ab", Focal: 5}, {Label: "pc", Focal: 6}},
+			label: "pc",
+			focal: 6,
+			ok:    true,
+		},
+		{
+			ops:   []Op{{Label: "qp", Remove: true}},
+			power: 145,
+			box3:  []Lens{{Label: "ot", Focal: 7}, {Label: "ab", Focal: 5}, {Label: "pc", Focal: 6}},
+			label: "qp",
+			focal: 0,
+			ok:    false,
+		},
+		{
+			ops:   []Op{{Label: "ot", Remove: true}},
+			power: 1 + 4 + 4*1*5 + 4*2*6,
+			box3:  []Lens{{Label: "ab", Focal: 5}, {Label: "pc", Focal: 6}},
+			label: "ot",
+			focal: 0,
+			ok:    false,
+		},
+		{
+			ops:   []Op{{Label: "ot", Remove: true}, {Label: "ot", Focal: 1}},
+			power: 1 + 4 + 4*1*5 + 4*2*6 + 4*3*1,
+			box3:  []Lens{{Label: "ab", Focal: 5}, {Label: "pc", Focal: 6}, {Label: "ot", Focal: 1}},
+			label: "ot",
+			focal: 1,
+			ok:    true,
+		},
+		{
+			ops:   []Op{{Label: "ab", Focal: 2}},
+			power: 145 - 4*2*3,
+			box3:  []Lens{{Label: "ot", Focal: 7}, {Label: "ab", Focal: 2}, {Label: "pc", Focal: 6}},
+			label: "ab",
+			focal: 2,
+			ok:    true,
+		},
+	} {
+		tc := tc
+		t.Run("lens map test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			m := Replay(append(parseExampleOps(t), tc.ops...), nil)
+			if v := m.FocusingPower(); v != tc.power {
+				t.Fatalf("Invalid output %d != %d", v, tc.power)
+			}
+			if v := m.Box(3); !reflect.DeepEqual(v, tc.box3) {
+				t.Fatalf("Invalid box %v != %v", v, tc.box3)
+			}
+			focal, ok := m.Get(tc.label)
+			if focal != tc.focal || ok != tc.ok {
+				t.Fatalf("Invalid lens %d %t != %d %t", focal, ok, tc.focal, tc.ok)
+			}
+		})
+	}
+}
+
+func TestLensMapDelete(t *testing.T) {
+	for tcn, tc := range []struct {
+		label string
+		exp   bool
+	}{
+		{label: "rn", exp: true},
+		{label: "ot", exp: true},
+		{label: "qp", exp: false},
+		{label: "zz", exp: false},
+	} {
+		tc := tc
+		t.Run("delete test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			m := Replay(parseExampleOps(t), nil)
+			if v := m.Delete(tc.label); v != tc.exp {
+				t.Fatalf("Invalid output %t != %t", v, tc.exp)
+			}
+			if _, ok := m.Get(tc.label); ok {
+				t.Fatalf("Invalid lens %s still present", tc.label)
+			}
+		})
+	}
+}
+
+func TestParseOp(t *testing.T) {
+	for tcn, tc := range []struct {
+		op  string
+		exp Op
+		err error
+	}{
+		{op: "rn=1", exp: Op{Label: "rn", Focal: 1}},
+		{op: "cm-", exp: Op{Label: "cm", Remove: true}},
+		{op: "", err: errOp},
+		{op: "rn", err: errOp},
+		{op: "rn=", err: errOp},
+		{op: "rn=x", err: errOp},
+		{op: "rn+1", err: errOp},
+	} {
+		tc := tc
+		t.Run("parse op test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			v, err := ParseOp([]byte(tc.op))
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("Invalid error %v != %v", err, tc.err)
+			}
+			if v != tc.exp {
+				t.Fatalf("Invalid output %+v != %+v", v, tc.exp)
+			}
+		})
+	}
+}
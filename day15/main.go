@@ -2,11 +2,11 @@ package main
 
 import (
 	"bytes"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"os"
-	"strconv"
 )
 
 const (
@@ -14,6 +14,9 @@ const (
 )
 
 func main() {
+	replay := flag.Bool("replay", false, "print the boxes after every step of the initialization sequence")
+	flag.Parse()
+
 	file, err := os.Open(puzzleInput)
 	if err != nil {
 		log.Fatalln(err)
@@ -31,70 +34,22 @@ func main() {
 	buf = bytes.TrimSpace(buf)
 	words := bytes.Split(buf, []byte{','})
 
-	var boxes [256]Box
 	sum := 0
+	ops := make([]Op, 0, len(words))
 	for _, i := range words {
 		sum += hashWord(i)
-		processInstr(i, boxes[:])
-	}
-	fmt.Println("Part 1:", sum)
-
-	sum = 0
-	for n, i := range boxes {
-		for k, j := range i.values {
-			sum += (n + 1) * (k + 1) * j.v
-		}
-	}
-	fmt.Println("Part 2:", sum)
-}
-
-type (
-	Box struct {
-		values []BoxValue
-	}
-
-	BoxValue struct {
-		id string
-		v  int
-	}
-)
-
-func processInstr(v []byte, m []Box) {
-	if r, ok := bytes.CutSuffix(v, []byte{'-'}); ok {
-		h := hashWord(r)
-		label := string(r)
-		for n, i := range m[h].values {
-			if i.id == label {
-				copy(m[h].values[n:], m[h].values[n+1:])
-				m[h].values = m[h].values[:len(m[h].values)-1]
-				return
-			}
-		}
-		return
-	}
-	if r, b, ok := bytes.Cut(v, []byte{'='}); ok {
-		num, err := strconv.Atoi(string(b))
+		op, err := ParseOp(i)
 		if err != nil {
 			log.Fatalln(err)
 		}
-		h := hashWord(r)
-		label := string(r)
-		for n, i := range m[h].values {
-			if i.id == label {
-				m[h].values[n] = BoxValue{
-					id: label,
-					v:  num,
-				}
-				return
-			}
-		}
-		m[h].values = append(m[h].values, BoxValue{
-			id: label,
-			v:  num,
-		})
-		return
+		ops = append(ops, op)
+	}
+	fmt.Println("Part 1:", sum)
+
+	if *replay {
+		writeReplay(os.Stdout, ops)
 	}
-	log.Fatalln("Malformed op")
+	fmt.Println("Part 2:", Replay(ops, nil).FocusingPower())
 }
 
 func hashWord(v []byte) int {
@@ -2,18 +2,28 @@ package main
 
 import (
 	"bufio"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"strconv"
 	"strings"
+
+	"github.com/xorkevin/advent2023/geom"
 )
 
 const (
 	puzzleInput = "input.txt"
+	svgWidth    = 800
 )
 
 func main() {
+	svgName := flag.String("svg", "", "write the trench of the dig plan to an svg file")
+	svg2Name := flag.String("svg2", "", "write the trench of the dig plan decoded from the colors to an svg file")
+	flag.Parse()
+
 	file, err := os.Open(puzzleInput)
 	if err != nil {
 		log.Fatalln(err)
@@ -24,126 +34,103 @@ func main() {
 		}
 	}()
 
-	current := Pos{x: 0, y: 0}
-	current2 := Pos{x: 0, y: 0}
-	area := 0
-	perimeter := 0
-	area2 := 0
-	perimeter2 := 0
+	plan, plan2 := readPlans(file)
+	if err := errors.Join(plan.Validate(), plan2.Validate()); err != nil {
+		log.Fatalln(err)
+	}
+	fmt.Println("Part 1:", plan.Path.LatticePoints())
+	fmt.Println("Part 2:", plan2.Path.LatticePoints())
+
+	if *svgName != "" {
+		writeSVG(*svgName, plan.Path)
+	}
+	if *svg2Name != "" {
+		writeSVG(*svg2Name, plan2.Path)
+	}
+}
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
+// readPlans reads the dig plan from the directions and lengths of each
+// instruction, and the dig plan decoded from the colors.
+func readPlans(r io.Reader) (DigPlan, DigPlan) {
+	plan := NewDigPlan("plan")
+	plan2 := NewDigPlan("color plan")
+
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
 		line := strings.Split(scanner.Text(), " ")
 		if len(line) != 3 {
 			log.Fatalln("Invalid line")
 		}
 		{
-			dir := line[0]
 			num, err := strconv.Atoi(line[1])
 			if err != nil {
 				log.Fatalln(err)
 			}
-			current = move(current, dir, num)
-			switch dir {
-			case "L":
-				area -= current.y * num
-			case "R":
-				area += current.y * num
-			}
-			perimeter += num
+			plan.Dig(lineNum, move(line[0], num))
 		}
 		{
 			line2 := strings.Trim(line[2], "(#)")
 			if len(line2) != 6 {
 				log.Fatalln("Invalid line2")
 			}
-			dir := line2[5]
 			num64, err := strconv.ParseInt(string(line2[:5]), 16, 64)
 			if err != nil {
 				log.Fatalln(err)
 			}
-			num := int(num64)
-			current2 = move2(current2, dir, num)
-			switch dir {
-			case '2':
-				area2 -= current2.y * num
-			case '0':
-				area2 += current2.y * num
-			}
-			perimeter2 += num
+			plan2.Dig(lineNum, move2(line2[5], int(num64)))
 		}
 	}
 
 	if err := scanner.Err(); err != nil {
 		log.Fatalln(err)
 	}
-
-	if perimeter%2 != 0 {
-		log.Fatalln("Perimeter not aligned to grid")
-	}
-	area = abs(area)
-	halfPerimeter := perimeter / 2
-	fmt.Println("Part 1:", area+halfPerimeter+1)
-
-	if perimeter2%2 != 0 {
-		log.Fatalln("Perimeter not aligned to grid")
-	}
-	area2 = abs(area2)
-	halfPerimeter2 := perimeter2 / 2
-	fmt.Println("Part 2:", area2+halfPerimeter2+1)
+	return plan, plan2
 }
 
-func abs(a int) int {
-	if a < 0 {
-		return -a
+// writeSVG writes the trench of a dig plan to an svg file.
+func writeSVG(name string, plan geom.Polygon) {
+	file, err := os.Create(name)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			log.Fatalln(err)
+		}
+	}()
+	if err := plan.WriteSVG(file, svgWidth); err != nil {
+		log.Fatalln(err)
 	}
-	return a
 }
 
-func move2(p Pos, dir byte, num int) Pos {
+func move2(dir byte, num int) geom.Point {
 	switch dir {
 	case '3':
-		p.y -= num
+		return geom.Point{X: 0, Y: -num}
 	case '1':
-		p.y += num
+		return geom.Point{X: 0, Y: num}
 	case '2':
-		p.x -= num
+		return geom.Point{X: -num, Y: 0}
 	case '0':
-		p.x += num
+		return geom.Point{X: num, Y: 0}
 	default:
 		log.Fatalln("Invalid dir")
 	}
-	return p
+	return geom.Point{}
 }
 
-func move(p Pos, dir string, num int) Pos {
+func move(dir string, num int) geom.Point {
 	switch dir {
 	case "U":
-		p.y -= num
+		return geom.Point{X: 0, Y: -num}
 	case "D":
-		p.y += num
+		return geom.Point{X: 0, Y: num}
 	case "L":
-		p.x -= num
+		return geom.Point{X: -num, Y: 0}
 	case "R":
-		p.x += num
+		return geom.Point{X: num, Y: 0}
 	default:
 		log.Fatalln("Invalid dir")
 	}
-	return p
-}
-
-type (
-	Pos struct {
-		x, y int
-	}
-)
-
-func getBounds(p, tl, br Pos) (Pos, Pos) {
-	return Pos{
-			x: min(tl.x, p.x),
-			y: min(tl.y, p.y),
-		}, Pos{
-			x: max(br.x, p.x),
-			y: max(br.y, p.y),
-		}
+	return geom.Point{}
 }
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/xorkevin/advent2023/geom"
+)
+
+type (
+	// DigPlan is the trench dug by a plan, with the line of the instruction
+	// that dug each edge.
+	DigPlan struct {
+		Name  string
+		Path  geom.Polygon
+		Lines []int
+	}
+)
+
+func NewDigPlan(name string) DigPlan {
+	return DigPlan{
+		Name: name,
+		Path: geom.Polygon{Points: []geom.Point{{X: 0, Y: 0}}},
+	}
+}
+
+// Dig moves the digger by delta for the instruction on a line.
+func (d *DigPlan) Dig(line int, delta geom.Point) {
+	d.Path.Move(delta)
+	d.Lines = append(d.Lines, line)
+}
+
+var errInvalidPlan = errors.New("Invalid dig plan")
+
+// Validate reports every instruction that moves zero meters, a plan that
+// does not return to the start, and every pair of instructions whose
+// trenches cross or overlap other than where one ends and the next begins.
+// Moves of zero meters are left out when looking for crossings, so that the
+// instructions around them are treated as adjacent.
+func (d DigPlan) Validate() error {
+	var errs []error
+	path := geom.Polygon{Points: []geom.Point{d.Path.Points[0]}}
+	var lines []int
+	for i := 0; i < d.Path.NumEdges(); i++ {
+		if d.Path.Edge(i).Len() == 0 {
+			errs = append(errs, fmt.Errorf("%w: %s: line %d moves zero meters", errInvalidPlan, d.Name, d.Lines[i]))
+			continue
+		}
+		path.Points = append(path.Points, d.Path.Points[i+1])
+		lines = append(lines, d.Lines[i])
+	}
+	if !d.Path.IsClosed() {
+		end := d.Path.Points[len(d.Path.Points)-1]
+		if len(d.Lines) == 0 {
+			errs = append(errs, fmt.Errorf("%w: %s: no instructions", errInvalidPlan, d.Name))
+		} else {
+			errs = append(errs, fmt.Errorf("%w: %s: ends at %d,%d after line %d instead of returning to the start", errInvalidPlan, d.Name, end.X, end.Y, d.Lines[len(d.Lines)-1]))
+		}
+	}
+	for _, i := range path.Intersections() {
+		a, b := i.Shared.A, i.Shared.B
+		if a == b {
+			errs = append(errs, fmt.Errorf("%w: %s: line %d crosses line %d at %d,%d", errInvalidPlan, d.Name, lines[i.I], lines[i.J], a.X, a.Y))
+		} else {
+			errs = append(errs, fmt.Errorf("%w: %s: line %d overlaps line %d from %d,%d to %d,%d", errInvalidPlan, d.Name, lines[i.I], lines[i.J], a.X, a.Y, b.X, b.Y))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const examplePlan = `R 6 (#70c710)
+D 5 (#0dc571)
+L 2 (#5713f0)
+D 2 (#d2c081)
+R 2 (#59c680)
+D 2 (#411b91)
+L 5 (#8ceee2)
+U 2 (#caa173)
+L 1 (#1b58a2)
+U 2 (#caa171)
+R 2 (#7807d2)
+U 3 (#a77fa3)
+L 2 (#015232)
+U 2 (#7a21e3)
+`
+
+func TestDigPlanExample(t *testing.T) {
+	plan, plan2 := readPlans(strings.NewReader(examplePlan))
+	for tcn, tc := range []struct {
+		plan DigPlan
+		exp  int
+	}{
+		{plan: plan, exp: 62},
+		{plan: plan2, exp: 952408144115},
+	} {
+		tc := tc
+		t.Run("dig plan test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			if err := tc.plan.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			if v := tc.plan.Path.LatticePoints(); v != tc.exp {
+				t.Fatalf("Invalid output %d != %d", v, tc.exp)
+			}
+		})
+	}
+}
+
+func TestDigPlanValidate(t *testing.T) {
+	for tcn, tc := range []struct {
+		input string
+		errs  []string
+		errs2 []string
+	}{
+		{
+			// crossing: R4 D2 L2 U4 L2 D2, and R4 D2 L4 U2 R0 U0
+			input: `R 4 (#000040)
+D 2 (#000021)
+L 2 (#000042)
+U 4 (#000023)
+L 2 (#000000)
+D 2 (#000003)
+`,
+			errs: []string{
+				"plan: line 1 crosses line 4 at 2,0",
+			},
+			errs2: []string{
+				"color plan: line 5 moves zero meters",
+				"color plan: line 6 moves zero meters",
+			},
+		},
+		{
+			// open
+			input: `R 2 (#000020)
+D 2 (#000021)
+L 2 (#000022)
+U 1 (#000013)
+`,
+			errs: []string{
+				"plan: ends at 0,1 after line 4 instead of returning to the start",
+			},
+			errs2: []string{
+				"color plan: ends at 0,1 after line 4 instead of returning to the start",
+			},
+		},
+		{
+			// overlap
+			input: `R 4 (#000020)
+L 2 (#000021)
+D 2 (#000022)
+L 2 (#000023)
+U 2 (#000000)
+R 0 (#000000)
+`,
+			errs: []string{
+				"plan: line 6 moves zero meters",
+				"plan: line 1 overlaps line 2 from 2,0 to 4,0",
+				"plan: line 1 crosses line 3 at 2,0",
+			},
+			errs2: []string{
+				"color plan: line 5 moves zero meters",
+				"color plan: line 6 moves zero meters",
+			},
+		},
+		{
+			// leading zero move, validated twice
+			input: `R 0 (#000000)
+R 4 (#000040)
+D 2 (#000021)
+L 4 (#000042)
+U 2 (#000023)
+`,
+			errs: []string{
+				"plan: line 1 moves zero meters",
+			},
+			errs2: []string{
+				"color plan: line 1 moves zero meters",
+			},
+		},
+	} {
+		tc := tc
+		t.Run("validate test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			plan, plan2 := readPlans(strings.NewReader(tc.input))
+			checkPlanErrs(t, plan, tc.errs)
+			checkPlanErrs(t, plan2, tc.errs2)
+		})
+	}
+}
+
+func checkPlanErrs(t *testing.T, plan DigPlan, exp []string) {
+	t.Helper()
+	points := slices.Clone(plan.Path.Points)
+	// validating again must report the same errors on an unchanged path
+	for k := 0; k < 2; k++ {
+		err := plan.Validate()
+		if !slices.Equal(plan.Path.Points, points) {
+			t.Fatalf("Invalid path %v != %v", plan.Path.Points, points)
+		}
+		if len(exp) == 0 {
+			if err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if !errors.Is(err, errInvalidPlan) {
+			t.Fatalf("Invalid error %v != %v", err, errInvalidPlan)
+		}
+		var errs []string
+		for _, i := range strings.Split(err.Error(), "\n") {
+			errs = append(errs, strings.TrimPrefix(i, errInvalidPlan.Error()+": "))
+		}
+		if !slices.Equal(errs, exp) {
+			t.Fatalf("Invalid output %q != %q", errs, exp)
+		}
+	}
+}
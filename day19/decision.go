@@ -0,0 +1,216 @@
+package main
+
+import (
+	"log"
+	"math"
+	"slices"
+
+	"github.com/xorkevin/advent2023/interval"
+)
+
+const (
+	nodeAccept int32 = -1
+	nodeReject int32 = -2
+)
+
+type (
+	// DecisionNode sends a part to Lt if its rating for Part is less than Imm
+	// and to Ge otherwise. Targets are indices into the node array, or
+	// nodeAccept and nodeReject.
+	DecisionNode struct {
+		Part uint8
+		Imm  int
+		Lt   int32
+		Ge   int32
+	}
+
+	// DecisionTree is a workflow graph compiled into a flat array of binary
+	// decision nodes.
+	DecisionTree struct {
+		Nodes []DecisionNode
+		Root  int32
+	}
+
+	decisionCompiler struct {
+		workflows []Workflow
+		ids       map[string]int
+		entries   []int32
+		state     []uint8
+		nodes     []DecisionNode
+		interned  map[DecisionNode]int32
+	}
+)
+
+const (
+	compileUnvisited = iota
+	compileVisiting
+	compileDone
+)
+
+// compileWorkflows compiles the workflows reachable from start. Workflows are
+// assigned integer IDs, rules that can never match given the earlier rules of
+// their workflow are removed, nodes whose branches lead to the same place are
+// elided, which inlines single rule workflows, and identical nodes are shared.
+func compileWorkflows(workflows map[string]Workflow, start string) DecisionTree {
+	c := decisionCompiler{
+		ids:      map[string]int{},
+		interned: map[DecisionNode]int32{},
+	}
+	names := make([]string, 0, len(workflows))
+	for k := range workflows {
+		names = append(names, k)
+	}
+	slices.Sort(names)
+	for _, i := range names {
+		c.ids[i] = len(c.workflows)
+		c.workflows = append(c.workflows, workflows[i])
+	}
+	c.entries = make([]int32, len(c.workflows))
+	c.state = make([]uint8, len(c.workflows))
+	root := c.target(start)
+	return DecisionTree{
+		Nodes: c.nodes,
+		Root:  root,
+	}
+}
+
+func (c *decisionCompiler) target(name string) int32 {
+	switch name {
+	case "A":
+		return nodeAccept
+	case "R":
+		return nodeReject
+	}
+	id, ok := c.ids[name]
+	if !ok {
+		log.Fatalln("Invalid workflow name")
+	}
+	switch c.state[id] {
+	case compileVisiting:
+		log.Fatalln("Workflow cycle")
+	case compileDone:
+		return c.entries[id]
+	}
+	c.state[id] = compileVisiting
+	c.entries[id] = c.compile(c.workflows[id])
+	c.state[id] = compileDone
+	return c.entries[id]
+}
+
+func (c *decisionCompiler) compile(wf Workflow) int32 {
+	// bounds are unlimited so that a rule is only dropped when the earlier
+	// rules of its workflow make it dead for every rating
+	var bounds [4]interval.Range
+	for n := range bounds {
+		bounds[n] = interval.Range{
+			Start: math.MinInt,
+			End:   math.MaxInt,
+		}
+	}
+	// conds holds the reachable conditional rules of the workflow, normalized
+	// to the form v < imm, and fallback is where a part goes once every
+	// condition has failed
+	type cond struct {
+		part  int
+		imm   int
+		neg   bool
+		match int32
+	}
+	var conds []cond
+	fallback := int32(0)
+	hasFallback := false
+	for _, rule := range wf.Rules {
+		if rule.Op == 0 {
+			fallback = c.target(rule.Target)
+			hasFallback = true
+			break
+		}
+		v := bounds[rule.Part]
+		var k cond
+		switch rule.Op {
+		case '<':
+			if v.Start >= rule.Imm {
+				continue
+			}
+			if v.End <= rule.Imm {
+				fallback = c.target(rule.Target)
+				hasFallback = true
+				break
+			}
+			k = cond{
+				part: rule.Part,
+				imm:  rule.Imm,
+				neg:  false,
+			}
+			bounds[rule.Part].Start = rule.Imm
+		case '>':
+			if v.End <= rule.Imm+1 {
+				continue
+			}
+			if v.Start > rule.Imm {
+				fallback = c.target(rule.Target)
+				hasFallback = true
+				break
+			}
+			k = cond{
+				part: rule.Part,
+				imm:  rule.Imm + 1,
+				neg:  true,
+			}
+			bounds[rule.Part].End = rule.Imm + 1
+		default:
+			log.Fatalln("Invalid rule op")
+		}
+		if hasFallback {
+			break
+		}
+		k.match = c.target(rule.Target)
+		conds = append(conds, k)
+	}
+	if !hasFallback {
+		log.Fatalln("Workflow has no default rule")
+	}
+
+	next := fallback
+	for i := len(conds) - 1; i >= 0; i-- {
+		k := conds[i]
+		if k.match == next {
+			continue
+		}
+		node := DecisionNode{
+			Part: uint8(k.part),
+			Imm:  k.imm,
+			Lt:   k.match,
+			Ge:   next,
+		}
+		if k.neg {
+			node.Lt, node.Ge = next, k.match
+		}
+		next = c.intern(node)
+	}
+	return next
+}
+
+func (c *decisionCompiler) intern(node DecisionNode) int32 {
+	if idx, ok := c.interned[node]; ok {
+		return idx
+	}
+	idx := int32(len(c.nodes))
+	c.nodes = append(c.nodes, node)
+	c.interned[node] = idx
+	return idx
+}
+
+// Accepts evaluates a part against the tree.
+func (t *DecisionTree) Accepts(stateMap [4]int) bool {
+	idx := t.Root
+	for idx >= 0 {
+		node := &t.Nodes[idx]
+		if stateMap[node.Part] < node.Imm {
+			idx = node.Lt
+		} else {
+			idx = node.Ge
+		}
+	}
+	return idx == nodeAccept
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const exampleWorkflows = `px{a<2006:qkq,m>2090:A,rfg}
+pv{a>1716:R,A}
+lnx{m>1548:A,A}
+rfg{s<537:gd,x>2440:R,A}
+qs{s>3448:A,lnx}
+qkq{x<1416:A,crn}
+crn{x>2662:A,R}
+in{s<1351:px,qqz}
+qqz{s>2770:qs,m<1801:hdj,R}
+gd{a>3333:R,R}
+hdj{m>838:A,pv}`
+
+func parseWorkflows(s string) map[string]Workflow {
+	workflows := map[string]Workflow{}
+	for _, i := range strings.Split(s, "\n") {
+		wf := parseWorkflow(i)
+		workflows[wf.Name] = wf
+	}
+	return workflows
+}
+
+func readInputWorkflows(tb testing.TB) map[string]Workflow {
+	tb.Helper()
+	file, err := os.Open(puzzleInput)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			tb.Fatal(err)
+		}
+	}()
+	workflows := map[string]Workflow{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break
+		}
+		wf := parseWorkflow(line)
+		workflows[wf.Name] = wf
+	}
+	if err := scanner.Err(); err != nil {
+		tb.Fatal(err)
+	}
+	return workflows
+}
+
+func genParts(n int) [][4]int {
+	r := rand.New(rand.NewSource(19))
+	parts := make([][4]int, n)
+	for i := range parts {
+		for j := range parts[i] {
+			// ratings well outside of 1..4000 so that rules that are dead
+			// only within that range are still checked
+			parts[i][j] = r.Intn(12000) - 4000
+		}
+	}
+	return parts
+}
+
+func TestDecisionTree(t *testing.T) {
+	for tcn, tc := range []struct {
+		workflows map[string]Workflow
+	}{
+		{
+			workflows: parseWorkflows(exampleWorkflows),
+		},
+		{
+			workflows: readInputWorkflows(t),
+		},
+		{
+			// rules that are dead only for ratings within 1..4000
+			workflows: parseWorkflows(`in{x<1:A,m>4000:A,a<2000:lo,R}
+lo{a<1:R,s>4000:R,A}`),
+		},
+	} {
+		tc := tc
+		t.Run("decision tree test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			tree := compileWorkflows(tc.workflows, "in")
+			for _, i := range genParts(100000) {
+				if a, b := tree.Accepts(i), runWorkflows(tc.workflows, "in", i); a != b {
+					t.Fatalf("Invalid output for %v %t != %t", i, a, b)
+				}
+			}
+		})
+	}
+}
+
+func TestDecisionTreeExample(t *testing.T) {
+	tree := compileWorkflows(parseWorkflows(exampleWorkflows), "in")
+	for tcn, tc := range []struct {
+		part [4]int
+		exp  bool
+	}{
+		{part: [4]int{787, 2655, 1222, 2876}, exp: true},
+		{part: [4]int{1679, 44, 2067, 496}, exp: false},
+		{part: [4]int{2036, 264, 79, 2244}, exp: true},
+		{part: [4]int{2461, 1339, 466, 291}, exp: false},
+		{part: [4]int{2127, 1623, 2188, 1013}, exp: true},
+		{part: [4]int{0, 4001, 0, 4001}, exp: true},
+		{part: [4]int{-5, 2655, 1222, 5000}, exp: true},
+	} {
+		tc := tc
+		t.Run("decision tree example "+strconv.Itoa(tcn), func(t *testing.T) {
+			if v := tree.Accepts(tc.part); v != tc.exp {
+				t.Fatalf("Invalid output %t != %t", v, tc.exp)
+			}
+		})
+	}
+}
+
+const benchParts = 1 << 22
+
+func BenchmarkRunWorkflows(b *testing.B) {
+	workflows := readInputWorkflows(b)
+	parts := genParts(benchParts)
+	b.ResetTimer()
+	count := 0
+	for i := 0; i < b.N; i++ {
+		if runWorkflows(workflows, "in", parts[i%len(parts)]) {
+			count++
+		}
+	}
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "parts/s")
+}
+
+func BenchmarkDecisionTree(b *testing.B) {
+	tree := compileWorkflows(readInputWorkflows(b), "in")
+	parts := genParts(benchParts)
+	b.ResetTimer()
+	count := 0
+	for i := 0; i < b.N; i++ {
+		if tree.Accepts(parts[i%len(parts)]) {
+			count++
+		}
+	}
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "parts/s")
+}
@@ -2,11 +2,15 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"log"
+	"math"
 	"os"
 	"strconv"
 	"strings"
+
+	"github.com/xorkevin/advent2023/interval"
 )
 
 const (
@@ -14,6 +18,14 @@ const (
 )
 
 func main() {
+	mode := flag.String("mode", "", "output mode: table, csv, or query (reads parts from stdin)")
+	flag.Parse()
+	switch *mode {
+	case "", "table", "csv", "query":
+	default:
+		log.Fatalln("Invalid mode")
+	}
+
 	file, err := os.Open(puzzleInput)
 	if err != nil {
 		log.Fatalln(err)
@@ -26,6 +38,7 @@ func main() {
 
 	sum := 0
 	workflows := map[string]Workflow{}
+	var tree DecisionTree
 
 	addWorkflows := true
 	scanner := bufio.NewScanner(file)
@@ -34,90 +47,17 @@ func main() {
 		if addWorkflows {
 			if line == "" {
 				addWorkflows = false
+				tree = compileWorkflows(workflows, "in")
 				continue
 			}
 
-			name, rest, ok := strings.Cut(line, "{")
-			if !ok {
-				log.Fatalln("Invalid workflow")
-			}
-			rest = strings.Trim(rest, "}")
-			ruleStrs := strings.Split(rest, ",")
-			rules := make([]Rule, 0, len(ruleStrs))
-			for _, i := range ruleStrs {
-				if lhs, rhs, ok := strings.Cut(i, ":"); ok {
-					if opIdx := strings.IndexAny(lhs, "<>"); opIdx >= 0 {
-						if opIdx == 0 {
-							log.Fatalln("No part name in rule")
-						}
-						imm, err := strconv.Atoi(lhs[opIdx+1:])
-						if err != nil {
-							log.Fatalln(err)
-						}
-						var part int
-						switch lhs[:opIdx] {
-						case "x":
-							part = 0
-						case "m":
-							part = 1
-						case "a":
-							part = 2
-						case "s":
-							part = 3
-						default:
-							log.Fatalln("Invalid part name")
-						}
-						rules = append(rules, Rule{
-							Part:   part,
-							Op:     lhs[opIdx],
-							Imm:    imm,
-							Target: rhs,
-						})
-						continue
-					}
-					log.Fatalln("Invalid rule condition")
-					continue
-				}
-				rules = append(rules, Rule{
-					Target: i,
-				})
-			}
-			workflows[name] = Workflow{
-				Name:  name,
-				Rules: rules,
-			}
+			wf := parseWorkflow(line)
+			workflows[wf.Name] = wf
 			continue
 		}
 
-		stateStr := strings.Trim(line, "{}")
-		stateMap := [4]int{}
-		rating := 0
-		for _, i := range strings.Split(stateStr, ",") {
-			lhs, rhs, ok := strings.Cut(i, "=")
-			if !ok {
-				log.Fatalln("Invalid state part assign")
-			}
-			num, err := strconv.Atoi(rhs)
-			if err != nil {
-				log.Fatalln(err)
-			}
-			var part int
-			switch lhs {
-			case "x":
-				part = 0
-			case "m":
-				part = 1
-			case "a":
-				part = 2
-			case "s":
-				part = 3
-			default:
-				log.Fatalln("Invalid part name")
-			}
-			stateMap[part] = num
-			rating += num
-		}
-		if runWorkflows(workflows, "in", stateMap) {
+		stateMap, rating := parsePart(line)
+		if tree.Accepts(stateMap) {
 			sum += rating
 		}
 	}
@@ -126,30 +66,112 @@ func main() {
 		log.Fatalln(err)
 	}
 
-	fmt.Println("Part 1:", sum)
+	fullSet := interval.NewSet(interval.Range{
+		Start: 1,
+		End:   4001,
+	})
+	fullRange := [4]interval.Set{fullSet, fullSet, fullSet, fullSet}
+
+	switch *mode {
+	case "":
+		fmt.Println("Part 1:", sum)
+		fmt.Println("Part 2:", runWorkflowRanges(workflows, "in", fullRange))
+	case "table", "csv":
+		region := NewRegion(mergeBoxes(collectWorkflowRanges(workflows, "in", fullRange, nil)))
+		boxes := mergeBoxes(region.Boxes())
+		if *mode == "csv" {
+			writeBoxesCSV(os.Stdout, boxes)
+		} else {
+			writeBoxesTable(os.Stdout, boxes)
+		}
+	case "query":
+		region := NewRegion(mergeBoxes(collectWorkflowRanges(workflows, "in", fullRange, nil)))
+		queryRegion(os.Stdin, os.Stdout, region)
+	}
+}
+
+func parseWorkflow(line string) Workflow {
+	name, rest, ok := strings.Cut(line, "{")
+	if !ok {
+		log.Fatalln("Invalid workflow")
+	}
+	rest = strings.Trim(rest, "}")
+	ruleStrs := strings.Split(rest, ",")
+	rules := make([]Rule, 0, len(ruleStrs))
+	for _, i := range ruleStrs {
+		if lhs, rhs, ok := strings.Cut(i, ":"); ok {
+			if opIdx := strings.IndexAny(lhs, "<>"); opIdx >= 0 {
+				if opIdx == 0 {
+					log.Fatalln("No part name in rule")
+				}
+				imm, err := strconv.Atoi(lhs[opIdx+1:])
+				if err != nil {
+					log.Fatalln(err)
+				}
+				var part int
+				switch lhs[:opIdx] {
+				case "x":
+					part = 0
+				case "m":
+					part = 1
+				case "a":
+					part = 2
+				case "s":
+					part = 3
+				default:
+					log.Fatalln("Invalid part name")
+				}
+				rules = append(rules, Rule{
+					Part:   part,
+					Op:     lhs[opIdx],
+					Imm:    imm,
+					Target: rhs,
+				})
+				continue
+			}
+			log.Fatalln("Invalid rule condition")
+			continue
+		}
+		rules = append(rules, Rule{
+			Target: i,
+		})
+	}
+	return Workflow{
+		Name:  name,
+		Rules: rules,
+	}
+}
 
-	fmt.Println("Part 2:", runWorkflowRanges(
-		workflows,
-		"in",
-		[4]Range{
-			{
-				Left:  1,
-				Right: 4001,
-			},
-			{
-				Left:  1,
-				Right: 4001,
-			},
-			{
-				Left:  1,
-				Right: 4001,
-			},
-			{
-				Left:  1,
-				Right: 4001,
-			},
-		},
-	))
+func parsePart(line string) ([4]int, int) {
+	stateStr := strings.Trim(line, "{}")
+	stateMap := [4]int{}
+	rating := 0
+	for _, i := range strings.Split(stateStr, ",") {
+		lhs, rhs, ok := strings.Cut(i, "=")
+		if !ok {
+			log.Fatalln("Invalid state part assign")
+		}
+		num, err := strconv.Atoi(rhs)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		var part int
+		switch lhs {
+		case "x":
+			part = 0
+		case "m":
+			part = 1
+		case "a":
+			part = 2
+		case "s":
+			part = 3
+		default:
+			log.Fatalln("Invalid part name")
+		}
+		stateMap[part] = num
+		rating += num
+	}
+	return stateMap, rating
 }
 
 type (
@@ -164,20 +186,34 @@ type (
 		Imm    int
 		Target string
 	}
+)
 
-	Range struct {
-		Left  int
-		Right int
+// ruleSet returns the ratings that satisfy the condition of a rule.
+func ruleSet(rule Rule) interval.Set {
+	switch rule.Op {
+	case '<':
+		return interval.NewSet(interval.Range{
+			Start: math.MinInt,
+			End:   rule.Imm,
+		})
+	case '>':
+		return interval.NewSet(interval.Range{
+			Start: rule.Imm + 1,
+			End:   math.MaxInt,
+		})
+	default:
+		log.Fatalln("Invalid rule op")
+		return interval.Set{}
 	}
-)
+}
 
-func runWorkflowRanges(workflows map[string]Workflow, current string, stateMap [4]Range) int {
+func runWorkflowRanges(workflows map[string]Workflow, current string, stateMap [4]interval.Set) int {
 	switch current {
 	case "A":
 		{
 			prod := 1
 			for _, v := range stateMap {
-				prod *= v.Right - v.Left
+				prod *= v.Len()
 			}
 			return prod
 		}
@@ -190,63 +226,70 @@ func runWorkflowRanges(workflows map[string]Workflow, current string, stateMap [
 	}
 	sum := 0
 	for _, rule := range wf.Rules {
-		if rule.Op != 0 {
-			v := stateMap[rule.Part]
-			switch rule.Op {
-			case '<':
-				if v.Right <= rule.Imm {
-					sum += runWorkflowRanges(workflows, rule.Target, stateMap)
-					return sum
-				} else if v.Left >= rule.Imm {
-				} else {
-					childStateMap := stateMap
-					childStateMap[rule.Part] = Range{
-						Left:  v.Left,
-						Right: rule.Imm,
-					}
-					sum += runWorkflowRanges(workflows, rule.Target, childStateMap)
-					if v.Right == rule.Imm {
-						return sum
-					}
-					childStateMap[rule.Part] = Range{
-						Left:  rule.Imm,
-						Right: v.Right,
-					}
-					stateMap = childStateMap
-				}
-			case '>':
-				if v.Left > rule.Imm {
-					sum += runWorkflowRanges(workflows, rule.Target, stateMap)
-					return sum
-				} else if v.Right <= rule.Imm+1 {
-				} else {
-					childStateMap := stateMap
-					childStateMap[rule.Part] = Range{
-						Left:  rule.Imm + 1,
-						Right: v.Right,
-					}
-					sum += runWorkflowRanges(workflows, rule.Target, childStateMap)
-					if v.Left == rule.Imm+1 {
-						return sum
-					}
-					childStateMap[rule.Part] = Range{
-						Left:  v.Left,
-						Right: rule.Imm + 1,
-					}
-					stateMap = childStateMap
-				}
-			default:
-				log.Fatalln("Invalid rule op")
-			}
-		} else {
+		if rule.Op == 0 {
 			sum += runWorkflowRanges(workflows, rule.Target, stateMap)
 			return sum
 		}
+		cond := ruleSet(rule)
+		v := stateMap[rule.Part]
+		if match := v.Intersect(cond); !match.IsEmpty() {
+			childStateMap := stateMap
+			childStateMap[rule.Part] = match
+			sum += runWorkflowRanges(workflows, rule.Target, childStateMap)
+		}
+		stateMap[rule.Part] = v.Subtract(cond)
+		if stateMap[rule.Part].IsEmpty() {
+			return sum
+		}
 	}
 	log.Fatalln("Workflow has no default rule")
 	return sum
 }
 
+func collectWorkflowRanges(workflows map[string]Workflow, current string, stateMap [4]interval.Set, res [][4]interval.Range) [][4]interval.Range {
+	switch current {
+	case "A":
+		return appendBoxes(res, stateMap, [4]interval.Range{}, 0)
+	case "R":
+		return res
+	}
+	wf, ok := workflows[current]
+	if !ok {
+		log.Fatalln("Invalid workflow name")
+	}
+	for _, rule := range wf.Rules {
+		if rule.Op == 0 {
+			return collectWorkflowRanges(workflows, rule.Target, stateMap, res)
+		}
+		cond := ruleSet(rule)
+		v := stateMap[rule.Part]
+		if match := v.Intersect(cond); !match.IsEmpty() {
+			childStateMap := stateMap
+			childStateMap[rule.Part] = match
+			res = collectWorkflowRanges(workflows, rule.Target, childStateMap, res)
+		}
+		stateMap[rule.Part] = v.Subtract(cond)
+		if stateMap[rule.Part].IsEmpty() {
+			return res
+		}
+	}
+	log.Fatalln("Workflow has no default rule")
+	return res
+}
+
+// appendBoxes appends every box in the product of the ranges of each part's
+// set.
+func appendBoxes(res [][4]interval.Range, stateMap [4]interval.Set, cur [4]interval.Range, dim int) [][4]interval.Range {
+	if dim == len(stateMap) {
+		return append(res, cur)
+	}
+	for _, i := range stateMap[dim].Ranges() {
+		cur[dim] = i
+		res = appendBoxes(res, stateMap, cur, dim+1)
+	}
+	return res
+}
+
 func runWorkflows(workflows map[string]Workflow, current string, stateMap [4]int) bool {
 	wf, ok := workflows[current]
 	if !ok {
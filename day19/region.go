@@ -0,0 +1,309 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/xorkevin/advent2023/interval"
+)
+
+var partNames = [4]string{"x", "m", "a", "s"}
+
+type (
+	// Region is a canonical decomposition of a union of boxes. Each level
+	// splits one part dimension into maximal slabs, where slab i covers
+	// [Bounds[i], Bounds[i+1]) and Children[i] is the region of the remaining
+	// dimensions within that slab. A nil child is an empty slab, and the last
+	// dimension points to fullRegion.
+	Region struct {
+		Bounds   []int
+		Children []*Region
+	}
+
+	regionBuilder struct {
+		boxes    [][4]interval.Range
+		ids      map[*Region]int
+		interned map[string]*Region
+		memo     map[string]*Region
+	}
+)
+
+var fullRegion = &Region{}
+
+// NewRegion merges disjoint boxes into a canonical region. Equal sub regions
+// are interned so that adjacent slabs with equal cross sections collapse into
+// one.
+func NewRegion(boxes [][4]interval.Range) *Region {
+	b := regionBuilder{
+		boxes: boxes,
+		ids: map[*Region]int{
+			nil:        -1,
+			fullRegion: 0,
+		},
+		interned: map[string]*Region{},
+		memo:     map[string]*Region{},
+	}
+	idxs := make([]int, len(boxes))
+	for n := range idxs {
+		idxs[n] = n
+	}
+	return b.build(idxs, 0)
+}
+
+func (b *regionBuilder) build(idxs []int, dim int) *Region {
+	if len(idxs) == 0 {
+		return nil
+	}
+	if dim == len(partNames) {
+		return fullRegion
+	}
+
+	memoKey := joinInts(dim, idxs, nil)
+	if r, ok := b.memo[memoKey]; ok {
+		return r
+	}
+
+	edges := make([]int, 0, len(idxs)*2)
+	for _, i := range idxs {
+		edges = append(edges, b.boxes[i][dim].Start, b.boxes[i][dim].End)
+	}
+	slices.Sort(edges)
+	edges = slices.Compact(edges)
+
+	bounds := []int{edges[0]}
+	var children []*Region
+	for n, left := range edges[:len(edges)-1] {
+		right := edges[n+1]
+		var sub []int
+		for _, i := range idxs {
+			if v := b.boxes[i][dim]; v.Start <= left && v.End >= right {
+				sub = append(sub, i)
+			}
+		}
+		child := b.build(sub, dim+1)
+		if len(children) > 0 && children[len(children)-1] == child {
+			bounds[len(bounds)-1] = right
+			continue
+		}
+		children = append(children, child)
+		bounds = append(bounds, right)
+	}
+	r := b.intern(dim, bounds, children)
+	b.memo[memoKey] = r
+	return r
+}
+
+func (b *regionBuilder) intern(dim int, bounds []int, children []*Region) *Region {
+	childIDs := make([]int, len(children))
+	for n, i := range children {
+		childIDs[n] = b.ids[i]
+	}
+	key := joinInts(dim, bounds, childIDs)
+	if r, ok := b.interned[key]; ok {
+		return r
+	}
+	r := &Region{
+		Bounds:   bounds,
+		Children: children,
+	}
+	b.interned[key] = r
+	b.ids[r] = len(b.ids)
+	return r
+}
+
+func joinInts(dim int, a, b []int) string {
+	var key strings.Builder
+	key.WriteString(strconv.Itoa(dim))
+	for _, i := range a {
+		key.WriteByte(',')
+		key.WriteString(strconv.Itoa(i))
+	}
+	key.WriteByte(';')
+	for _, i := range b {
+		key.WriteByte(',')
+		key.WriteString(strconv.Itoa(i))
+	}
+	return key.String()
+}
+
+// Contains reports whether a part lies in the region with one binary search
+// per dimension.
+func (r *Region) Contains(stateMap [4]int) bool {
+	for _, v := range stateMap {
+		if r == nil {
+			return false
+		}
+		idx := sort.SearchInts(r.Bounds, v+1) - 1
+		if idx < 0 || idx >= len(r.Children) {
+			return false
+		}
+		r = r.Children[idx]
+	}
+	return r == fullRegion
+}
+
+// Boxes returns the disjoint boxes of the region in sorted order.
+func (r *Region) Boxes() [][4]interval.Range {
+	var res [][4]interval.Range
+	var cur [4]interval.Range
+	var walk func(r *Region, dim int)
+	walk = func(r *Region, dim int) {
+		if r == nil {
+			return
+		}
+		if r == fullRegion {
+			res = append(res, cur)
+			return
+		}
+		for n, i := range r.Children {
+			cur[dim] = interval.Range{
+				Start: r.Bounds[n],
+				End:   r.Bounds[n+1],
+			}
+			walk(i, dim+1)
+		}
+	}
+	walk(r, 0)
+	return res
+}
+
+func writeBoxesTable(w io.Writer, boxes [][4]interval.Range) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(partNames[:], "\t")+"\tcount")
+	total := 0
+	for _, box := range boxes {
+		count := 1
+		for _, v := range box {
+			fmt.Fprintf(tw, "%d-%d\t", v.Start, v.End-1)
+			count *= v.End - v.Start
+		}
+		fmt.Fprintln(tw, count)
+		total += count
+	}
+	if err := tw.Flush(); err != nil {
+		log.Fatalln(err)
+	}
+	fmt.Fprintln(w, "Total:", total)
+}
+
+func writeBoxesCSV(w io.Writer, boxes [][4]interval.Range) {
+	cw := csv.NewWriter(w)
+	header := make([]string, 0, len(partNames)*2)
+	for _, i := range partNames {
+		header = append(header, i+"_min", i+"_max")
+	}
+	if err := cw.Write(header); err != nil {
+		log.Fatalln(err)
+	}
+	record := make([]string, len(header))
+	for _, box := range boxes {
+		for n, v := range box {
+			record[n*2] = strconv.Itoa(v.Start)
+			record[n*2+1] = strconv.Itoa(v.End - 1)
+		}
+		if err := cw.Write(record); err != nil {
+			log.Fatalln(err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func queryRegion(r io.Reader, w io.Writer, region *Region) {
+	bw := bufio.NewWriter(w)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		stateMap, _ := parsePart(line)
+		res := "R"
+		if region.Contains(stateMap) {
+			res = "A"
+		}
+		fmt.Fprintln(bw, line, res)
+	}
+	if err := scanner.Err(); err != nil {
+		log.Fatalln(err)
+	}
+	if err := bw.Flush(); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// mergeBoxes coalesces boxes that are equal in all but one dimension and
+// adjacent in the remaining one until no more merges are possible. The result
+// is sorted, so merging the boxes of a canonical region yields a canonical
+// list of boxes.
+func mergeBoxes(boxes [][4]interval.Range) [][4]interval.Range {
+	boxes = slices.Clone(boxes)
+	for changed := true; changed; {
+		changed = false
+		for dim := len(partNames) - 1; dim >= 0; dim-- {
+			slices.SortFunc(boxes, func(a, b [4]interval.Range) int {
+				for n := range a {
+					if n == dim {
+						continue
+					}
+					if c := cmpRange(a[n], b[n]); c != 0 {
+						return c
+					}
+				}
+				return cmpRange(a[dim], b[dim])
+			})
+			res := boxes[:0]
+			for _, i := range boxes {
+				if len(res) > 0 {
+					last := &res[len(res)-1]
+					if canMergeBoxes(*last, i, dim) {
+						last[dim].End = i[dim].End
+						changed = true
+						continue
+					}
+				}
+				res = append(res, i)
+			}
+			boxes = res
+		}
+	}
+	slices.SortFunc(boxes, func(a, b [4]interval.Range) int {
+		for n := range a {
+			if c := cmpRange(a[n], b[n]); c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	return boxes
+}
+
+func canMergeBoxes(a, b [4]interval.Range, dim int) bool {
+	for n := range a {
+		if n == dim {
+			if a[n].End != b[n].Start {
+				return false
+			}
+		} else if a[n] != b[n] {
+			return false
+		}
+	}
+	return true
+}
+
+func cmpRange(a, b interval.Range) int {
+	if a.Start != b.Start {
+		return a.Start - b.Start
+	}
+	return a.End - b.End
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/xorkevin/advent2023/interval"
+)
+
+const exampleParts = `{x=787,m=2655,a=1222,s=2876}
+{x=1679,m=44,a=2067,s=496}
+{x=2036,m=264,a=79,s=2244}
+{x=2461,m=1339,a=466,s=291}
+{x=2127,m=1623,a=2188,s=1013}`
+
+func exampleRegion() (map[string]Workflow, [][4]interval.Range, *Region) {
+	workflows := parseWorkflows(exampleWorkflows)
+	fullSet := interval.NewSet(interval.Range{
+		Start: 1,
+		End:   4001,
+	})
+	fullRange := [4]interval.Set{fullSet, fullSet, fullSet, fullSet}
+	boxes := collectWorkflowRanges(workflows, "in", fullRange, nil)
+	return workflows, boxes, NewRegion(mergeBoxes(boxes))
+}
+
+func boxesVolume(boxes [][4]interval.Range) int {
+	total := 0
+	for _, box := range boxes {
+		count := 1
+		for _, v := range box {
+			count *= v.End - v.Start
+		}
+		total += count
+	}
+	return total
+}
+
+func TestRegionVolume(t *testing.T) {
+	_, boxes, region := exampleRegion()
+	for tcn, tc := range []struct {
+		boxes [][4]interval.Range
+		exp   int
+	}{
+		{
+			boxes: boxes,
+			exp:   167409079868000,
+		},
+		{
+			boxes: mergeBoxes(boxes),
+			exp:   167409079868000,
+		},
+		{
+			boxes: region.Boxes(),
+			exp:   167409079868000,
+		},
+		{
+			boxes: mergeBoxes(region.Boxes()),
+			exp:   167409079868000,
+		},
+	} {
+		tc := tc
+		t.Run("region volume test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			if v := boxesVolume(tc.boxes); v != tc.exp {
+				t.Fatalf("Invalid output %d != %d", v, tc.exp)
+			}
+		})
+	}
+}
+
+func TestRegionContains(t *testing.T) {
+	workflows, _, region := exampleRegion()
+	for tcn, tc := range []struct {
+		part [4]int
+		exp  bool
+	}{
+		{part: [4]int{787, 2655, 1222, 2876}, exp: true},
+		{part: [4]int{1679, 44, 2067, 496}, exp: false},
+		{part: [4]int{2036, 264, 79, 2244}, exp: true},
+		{part: [4]int{2461, 1339, 466, 291}, exp: false},
+		{part: [4]int{2127, 1623, 2188, 1013}, exp: true},
+		{part: [4]int{1, 1, 1, 1}, exp: true},
+		{part: [4]int{4000, 4000, 4000, 4000}, exp: true},
+		{part: [4]int{4000, 1, 4000, 1}, exp: false},
+	} {
+		tc := tc
+		t.Run("region contains test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			if v := runWorkflows(workflows, "in", tc.part); v != tc.exp {
+				t.Fatalf("Invalid workflow output %t != %t", v, tc.exp)
+			}
+			if v := region.Contains(tc.part); v != tc.exp {
+				t.Fatalf("Invalid output %t != %t", v, tc.exp)
+			}
+		})
+	}
+}
+
+func TestQueryRegion(t *testing.T) {
+	_, _, region := exampleRegion()
+	for tcn, tc := range []struct {
+		parts string
+		exp   int
+	}{
+		{
+			parts: exampleParts,
+			exp:   19114,
+		},
+		{
+			parts: "\n" + exampleParts + "\n\n",
+			exp:   19114,
+		},
+		{
+			parts: "{x=1679,m=44,a=2067,s=496}",
+			exp:   0,
+		},
+	} {
+		tc := tc
+		t.Run("query test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			var out bytes.Buffer
+			queryRegion(strings.NewReader(tc.parts), &out, region)
+			sum := 0
+			scanner := bufio.NewScanner(&out)
+			for scanner.Scan() {
+				line, res, ok := strings.Cut(scanner.Text(), " ")
+				if !ok {
+					t.Fatalf("Invalid query line %q", scanner.Text())
+				}
+				if res == "A" {
+					_, rating := parsePart(line)
+					sum += rating
+				}
+			}
+			if v := sum; v != tc.exp {
+				t.Fatalf("Invalid output %d != %d", v, tc.exp)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -10,9 +11,15 @@ import (
 
 const (
 	puzzleInput = "input.txt"
+	// maxCheckSteps bounds the brute force cross check
+	maxCheckSteps = 5000
 )
 
 func main() {
+	target := flag.Int("steps", 26501365, "number of steps on the tiled map")
+	check := flag.Bool("check", false, "cross check the tiled map count with a brute force search")
+	flag.Parse()
+
 	file, err := os.Open(puzzleInput)
 	if err != nil {
 		log.Fatalln(err)
@@ -43,7 +50,73 @@ func main() {
 
 	height := len(grid)
 	width := len(grid[0])
-	const target = 26501365
+
+	closedSet := make([]bool, width*height)
+	openSet := NewRing[State](width * height)
+	closedSet[start.y*width+start.x] = true
+	openSet.Write(State{
+		pos: start,
+		g:   0,
+	})
+	const p1Target = 64
+	const p1TargetIsEven = p1Target%2 == 0
+	sum := 0
+	for {
+		s, ok := openSet.Read()
+		if !ok {
+			break
+		}
+		closedSet[s.pos.y*width+s.pos.x] = true
+		curIsEven := s.g%2 == 0
+		if s.g <= p1Target && curIsEven == p1TargetIsEven {
+			sum++
+		}
+		var neighbors [4]Pos
+		n := getNeighbors(grid, width, height, s.pos, neighbors[:])
+		for _, i := range neighbors[:n] {
+			key := i.y*width + i.x
+			if closedSet[key] {
+				continue
+			}
+			closedSet[key] = true
+			openSet.Write(State{
+				pos: i,
+				g:   s.g + 1,
+			})
+		}
+	}
+	fmt.Println("Part 1:", sum)
+	fmt.Println("Part 2:", countReachableTiled(grid, start, *target))
+
+	if *check {
+		if *target > maxCheckSteps {
+			log.Fatalln("Too many steps to check by brute force")
+		}
+		fmt.Println("Brute force:", NewTiledBFS(grid, start).Reachable(*target))
+		if v, ok := countReachableDiamond(grid, start, *target); ok {
+			fmt.Println("Diamond:", v)
+		}
+	}
+}
+
+// countReachableDiamond counts the plots reachable in exactly target steps
+// for a square grid with a centered start and a target that ends at the edge
+// of a tile, by counting the plots of each parity inside and outside of the
+// diamond reachable within the final partial tile. It returns false if the
+// grid and target do not have that shape.
+func countReachableDiamond(grid [][]byte, start Pos, target int) (int, bool) {
+	height := len(grid)
+	width := len(grid[0])
+	if height != width {
+		return 0, false
+	}
+	if height%2 != 1 || start.y != (height-1)/2 || start.x != start.y {
+		return 0, false
+	}
+	if target%height != start.y {
+		return 0, false
+	}
+
 	multiple := target / height
 	rem := target % height
 
@@ -59,9 +132,6 @@ func main() {
 		pos: start,
 		g:   0,
 	})
-	const p1Target = 64
-	const p1TargetIsEven = p1Target%2 == 0
-	sum := 0
 	for {
 		s, ok := openSet.Read()
 		if !ok {
@@ -82,9 +152,6 @@ func main() {
 				innerOdd++
 			}
 		}
-		if s.g <= p1Target && curIsEven == p1TargetIsEven {
-			sum++
-		}
 		var neighbors [4]Pos
 		n := getNeighbors(grid, width, height, s.pos, neighbors[:])
 		for _, i := range neighbors[:n] {
@@ -99,16 +166,8 @@ func main() {
 			})
 		}
 	}
-	fmt.Println("Part 1:", sum)
-
-	if height != width {
-		log.Fatalln("Grid is not square")
-	}
-	if height%2 != 1 || start.y != (height-1)/2 || start.x != start.y {
-		log.Fatalln("Start is not centered")
-	}
 
-	const targetIsEven = target%2 == 0
+	targetIsEven := target%2 == 0
 	multipleIsEven := multiple%2 == 0
 	multiple1 := multiple + 1
 	outerMultiple := multiple1 * multiple1
@@ -122,7 +181,7 @@ func main() {
 		outerDiamond, innerDiamond = innerDiamond, outerDiamond
 		outerCorner, innerCorner = innerCorner, outerCorner
 	}
-	fmt.Println("Part 2:", outerMultiple*outerDiamond+innerMultiple*innerDiamond+(outerMultiple-multiple1)*outerCorner+(innerMultiple+multiple)*innerCorner)
+	return outerMultiple*outerDiamond + innerMultiple*innerDiamond + (outerMultiple-multiple1)*outerCorner + (innerMultiple+multiple)*innerCorner, true
 }
 
 func manhattanDistance(a, b Pos) int {
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+)
+
+type (
+	diamondInput struct {
+		grid   [][]byte
+		start  Pos
+		target int
+	}
+)
+
+// Generate creates a small map with the shape the diamond formula relies
+// on: a square grid with a centered start, clear middle row, column and
+// border, and isolated rocks so that every plot in the tile is reachable
+// along a shortest grid path. The target ends at the edge of a tile.
+func (diamondInput) Generate(r *rand.Rand, size int) reflect.Value {
+	n := r.Intn(6)*2 + 5
+	mid := n / 2
+	grid := make([][]byte, n)
+	for y := range grid {
+		grid[y] = make([]byte, n)
+		for x := range grid[y] {
+			grid[y][x] = '.'
+		}
+	}
+	for y := 1; y < n-1; y++ {
+		for x := 1; x < n-1; x++ {
+			if x == mid || y == mid || r.Intn(3) != 0 {
+				continue
+			}
+			isolated := true
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					if grid[y+dy][x+dx] == '#' {
+						isolated = false
+					}
+				}
+			}
+			if isolated {
+				grid[y][x] = '#'
+			}
+		}
+	}
+	grid[mid][mid] = 'S'
+	return reflect.ValueOf(diamondInput{
+		grid: grid,
+		start: Pos{
+			x: mid,
+			y: mid,
+		},
+		target: r.Intn(5)*n + mid,
+	})
+}
+
+func TestCountReachableDiamond(t *testing.T) {
+	if err := quick.CheckEqual(
+		func(in diamondInput) int {
+			v, ok := countReachableDiamond(in.grid, in.start, in.target)
+			if !ok {
+				return -1
+			}
+			return v
+		},
+		func(in diamondInput) int {
+			return NewTiledBFS(in.grid, in.start).Reachable(in.target)
+		},
+		&quick.Config{
+			MaxCount: 2048,
+		},
+	); err != nil {
+		t.Fatal(err)
+	}
+}
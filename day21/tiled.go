@@ -0,0 +1,184 @@
+package main
+
+import (
+	"log"
+)
+
+const (
+	// maxPeriods is the number of periods searched for polynomial growth
+	// before giving up
+	maxPeriods = 16
+	// maxOrder is the highest degree of polynomial growth that is detected
+	maxOrder = 4
+	// growthConfirm is the number of consecutive equal differences required
+	// before growth is assumed to be polynomial
+	growthConfirm = 3
+)
+
+type (
+	// TiledBFS is a breadth first search over the infinitely tiled grid that
+	// can be advanced one step at a time. Since every step on the grid changes
+	// the parity of x+y, a cell first reached at distance d can only be
+	// revisited at distance d-1 or d+1, so only the last two layers need to be
+	// kept.
+	TiledBFS struct {
+		grid  [][]byte
+		w, h  int
+		prev  map[Pos]struct{}
+		cur   map[Pos]struct{}
+		dist  int
+		reach [2]int
+	}
+)
+
+func NewTiledBFS(grid [][]byte, start Pos) *TiledBFS {
+	return &TiledBFS{
+		grid: grid,
+		w:    len(grid[0]),
+		h:    len(grid),
+		prev: map[Pos]struct{}{},
+		cur: map[Pos]struct{}{
+			start: {},
+		},
+		dist:  0,
+		reach: [2]int{1, 0},
+	}
+}
+
+func (b *TiledBFS) step() {
+	next := make(map[Pos]struct{}, len(b.cur)+len(b.prev))
+	for i := range b.cur {
+		for _, v := range [4]Pos{
+			{x: i.x, y: i.y - 1},
+			{x: i.x - 1, y: i.y},
+			{x: i.x, y: i.y + 1},
+			{x: i.x + 1, y: i.y},
+		} {
+			if _, ok := b.prev[v]; ok {
+				continue
+			}
+			if b.grid[mod(v.y, b.h)][mod(v.x, b.w)] == '#' {
+				continue
+			}
+			next[v] = struct{}{}
+		}
+	}
+	b.prev = b.cur
+	b.cur = next
+	b.dist++
+	b.reach[b.dist%2] += len(next)
+}
+
+// Reachable returns the number of plots that can be reached in exactly n
+// steps, which are the plots whose distance is at most n and has the same
+// parity as n. n may not be less than any previously requested n.
+func (b *TiledBFS) Reachable(n int) int {
+	if n < b.dist {
+		log.Fatalln("Tiled search cannot go backwards")
+	}
+	for b.dist < n {
+		b.step()
+	}
+	return b.reach[n%2]
+}
+
+// countReachableTiled returns the number of plots that can be reached in
+// exactly target steps on the infinitely tiled grid. It samples the count at
+// target-k*p for increasing k, where p is a period of the tiling, until the
+// samples are detected to grow as a polynomial, and then extrapolates the
+// polynomial exactly to target. An odd period is retried doubled in case the
+// growth alternates with the parity of k.
+func countReachableTiled(grid [][]byte, start Pos, target int) int {
+	w := len(grid[0])
+	h := len(grid)
+	period := w / gcd(w, h) * h
+	for _, p := range []int{period, period * 2} {
+		if v, ok := extrapolateReachable(grid, start, target, p); ok {
+			return v
+		}
+	}
+	log.Fatalln("Failed to detect polynomial growth")
+	return 0
+}
+
+func extrapolateReachable(grid [][]byte, start Pos, target int, period int) (int, bool) {
+	rem := target % period
+	n := target / period
+	b := NewTiledBFS(grid, start)
+	var samples []int
+	for k := 0; k <= maxPeriods; k++ {
+		samples = append(samples, b.Reachable(rem+k*period))
+		if k == n {
+			return samples[k], true
+		}
+		if order, ok := detectPolynomial(samples); ok {
+			return extendPolynomial(samples, order, n), true
+		}
+	}
+	return 0, false
+}
+
+// detectPolynomial returns the smallest order whose differences have been
+// constant for the last growthConfirm samples.
+func detectPolynomial(samples []int) (int, bool) {
+	diffs := samples
+	for order := 1; order <= maxOrder; order++ {
+		next := make([]int, len(diffs)-1)
+		for i := range next {
+			next[i] = diffs[i+1] - diffs[i]
+		}
+		diffs = next
+		if len(diffs) < growthConfirm {
+			return 0, false
+		}
+		last := diffs[len(diffs)-growthConfirm:]
+		isConst := true
+		for _, i := range last[1:] {
+			if i != last[0] {
+				isConst = false
+				break
+			}
+		}
+		if isConst {
+			return order, true
+		}
+	}
+	return 0, false
+}
+
+// extendPolynomial extends samples to index n using the trailing difference
+// table of the given order.
+func extendPolynomial(samples []int, order int, n int) int {
+	// table[i] holds the last i-th difference
+	table := make([]int, order+1)
+	diffs := samples
+	for i := 0; i <= order; i++ {
+		table[i] = diffs[len(diffs)-1]
+		next := make([]int, len(diffs)-1)
+		for j := range next {
+			next[j] = diffs[j+1] - diffs[j]
+		}
+		diffs = next
+	}
+	for k := len(samples) - 1; k < n; k++ {
+		for i := order - 1; i >= 0; i-- {
+			table[i] += table[i+1]
+		}
+	}
+	return table[0]
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+func mod(a, m int) int {
+	a %= m
+	if a < 0 {
+		a += m
+	}
+	return a
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+)
+
+const exampleGrid = `...........
+.....###.#.
+.###.##..#.
+..#.#...#..
+....#.#....
+.##..S####.
+.##..#...#.
+.......##..
+.##.#.####.
+.##..##.##.
+...........`
+
+func parseGrid(s string) ([][]byte, Pos) {
+	grid := bytes.Split([]byte(s), []byte("\n"))
+	var start Pos
+	for y, i := range grid {
+		if x := bytes.IndexByte(i, 'S'); x >= 0 {
+			start = Pos{
+				x: x,
+				y: y,
+			}
+		}
+	}
+	return grid, start
+}
+
+func TestCountReachableTiled(t *testing.T) {
+	grid, start := parseGrid(exampleGrid)
+	for _, tc := range []struct {
+		steps int
+		exp   int
+	}{
+		{steps: 6, exp: 16},
+		{steps: 10, exp: 50},
+		{steps: 50, exp: 1594},
+		{steps: 100, exp: 6536},
+		{steps: 500, exp: 167004},
+		{steps: 1000, exp: 668697},
+		{steps: 5000, exp: 16733044},
+	} {
+		tc := tc
+		t.Run("tiled steps "+strconv.Itoa(tc.steps), func(t *testing.T) {
+			if v := countReachableTiled(grid, start, tc.steps); v != tc.exp {
+				t.Fatalf("Invalid output %d != %d", v, tc.exp)
+			}
+			if tc.steps > 1000 {
+				return
+			}
+			if v := NewTiledBFS(grid, start).Reachable(tc.steps); v != tc.exp {
+				t.Fatalf("Invalid brute force output %d != %d", v, tc.exp)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -15,6 +16,19 @@ const (
 )
 
 func main() {
+	side := flag.String("side", "", "print the settled tower viewed from the side, with x or y going left to right")
+	mesh := flag.String("mesh", "", "write the settled tower as an obj or ply mesh")
+	topple := flag.Int("topple", 0, "highlight the bricks that fall if the brick on this input line is disintegrated")
+	flag.Parse()
+
+	// the side view and the mesh are both written to stdout
+	if *side != "" && *mesh != "" {
+		log.Fatalln("Invalid side with mesh")
+	}
+	if *topple != 0 && *side == "" && *mesh == "" {
+		log.Fatalln("Invalid topple without side or mesh")
+	}
+
 	file, err := os.Open(puzzleInput)
 	if err != nil {
 		log.Fatalln(err)
@@ -83,6 +97,7 @@ func main() {
 			a:      lhsPos,
 			b:      rhsPos,
 			height: rhsPos.z - lhsPos.z,
+			line:   len(lines) + 1,
 		})
 
 		if first {
@@ -110,19 +125,45 @@ func main() {
 	xwidth := maxX - minX + 1
 	ywidth := maxY - minY + 1
 
-	count := 0
+	tower := settleTower(minX, minY, xwidth, ywidth, lines)
+
+	if *side != "" || *mesh != "" {
+		chosen := -1
+		var falls []bool
+		if *topple != 0 {
+			chosen = slices.IndexFunc(tower.Bricks, func(i Line) bool {
+				return i.line == *topple
+			})
+			if chosen < 0 {
+				log.Fatalln("Invalid brick line")
+			}
+			falls = tower.Topples(chosen)
+		}
+		switch *side {
+		case "":
+		case "x":
+			writeSide(os.Stdout, tower, 0, chosen, falls)
+		case "y":
+			writeSide(os.Stdout, tower, 1, chosen, falls)
+		default:
+			log.Fatalln("Invalid side axis")
+		}
+		switch *mesh {
+		case "":
+		case "obj":
+			writeOBJ(os.Stdout, tower, chosen, falls)
+		case "ply":
+			writePLY(os.Stdout, tower, chosen, falls)
+		default:
+			log.Fatalln("Invalid mesh format")
+		}
+		return
+	}
+
+	count := tower.SafeCount()
 	sum := 0
-	heightMap := make([]int, xwidth*ywidth)
-	fullTower := make([]Line, len(lines))
-	getTower(minX, minY, xwidth, heightMap, lines, -1, fullTower)
-	candidate := make([]Line, len(lines))
-	for n := range lines {
-		getTower(minX, minY, xwidth, heightMap, lines, n, candidate)
-		delta := towerDelta(fullTower, candidate, n)
-		if delta == 0 {
-			count++
-		}
-		sum += delta
+	for _, i := range tower.ChainReactions() {
+		sum += i
 	}
 	fmt.Println("Part 1:", count)
 	fmt.Println("Part 2:", sum)
@@ -196,6 +237,7 @@ type (
 	Line struct {
 		a, b   Pos
 		height int
+		line   int
 	}
 
 	Pos struct {
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+)
+
+// Topples returns which bricks would fall if brick n were disintegrated,
+// which are those dominated by n in the support graph.
+func (t Tower) Topples(n int) []bool {
+	idom := t.Dominators()
+	falls := make([]bool, len(t.Bricks))
+	for v, d := range idom {
+		if d == len(t.Bricks) {
+			continue
+		}
+		falls[v] = d == n || falls[d]
+	}
+	return falls
+}
+
+func brickLabel(n int) byte {
+	return byte('A' + n%26)
+}
+
+// writeSide writes the tower viewed from the side in the style of the puzzle
+// statement, with axis 0 looking along y so that x goes left to right, and
+// axis 1 looking along x. Cells hiding more than one brick are drawn as ?.
+// If chosen is a brick, it is drawn as @ and the bricks that would fall
+// without it as !, regardless of what is in front of them.
+func writeSide(w io.Writer, t Tower, axis int, chosen int, falls []bool) {
+	lo, hi, top := 0, 0, 0
+	for n, i := range t.Bricks {
+		a, b := i.a.x, i.b.x
+		if axis == 1 {
+			a, b = i.a.y, i.b.y
+		}
+		if n == 0 {
+			lo, hi = a, b
+		} else {
+			lo = min(lo, a)
+			hi = max(hi, b)
+		}
+		top = max(top, i.b.z)
+	}
+	width := hi - lo + 1
+
+	const (
+		cellEmpty = -1
+		cellMany  = -2
+	)
+	cells := make([]int, width*(top+1))
+	for n := range cells {
+		cells[n] = cellEmpty
+	}
+	marks := make([]byte, width*(top+1))
+	for n, i := range t.Bricks {
+		a, b := i.a.x, i.b.x
+		if axis == 1 {
+			a, b = i.a.y, i.b.y
+		}
+		var mark byte
+		if n == chosen {
+			mark = '@'
+		} else if falls != nil && falls[n] {
+			mark = '!'
+		}
+		for z := i.a.z; z <= i.b.z; z++ {
+			for p := a; p <= b; p++ {
+				key := z*width + p - lo
+				switch cells[key] {
+				case cellEmpty, n:
+					cells[key] = n
+				default:
+					cells[key] = cellMany
+				}
+				if mark == '@' || mark == '!' && marks[key] != '@' {
+					marks[key] = mark
+				}
+			}
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	axisName := "x"
+	if axis == 1 {
+		axisName = "y"
+	}
+	fmt.Fprintf(bw, "%*s\n", width/2+1, axisName)
+	for p := 0; p < width; p++ {
+		bw.WriteByte(byte('0' + (lo+p)%10))
+	}
+	bw.WriteByte('\n')
+	for z := top; z >= 1; z-- {
+		for p := 0; p < width; p++ {
+			key := z*width + p
+			switch {
+			case marks[key] != 0:
+				bw.WriteByte(marks[key])
+			case cells[key] == cellEmpty:
+				bw.WriteByte('.')
+			case cells[key] == cellMany:
+				bw.WriteByte('?')
+			default:
+				bw.WriteByte(brickLabel(cells[key]))
+			}
+		}
+		bw.WriteByte(' ')
+		bw.WriteString(strconv.Itoa(z))
+		if z == (top+1)/2 {
+			bw.WriteString(" z")
+		}
+		bw.WriteByte('\n')
+	}
+	for p := 0; p < width; p++ {
+		bw.WriteByte('-')
+	}
+	bw.WriteString(" 0\n")
+	if err := bw.Flush(); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// boxFaces lists the corners of each face of a unit box, wound
+// counterclockwise when viewed from outside. Corner bit 0 selects the max x,
+// bit 1 the max y, and bit 2 the max z.
+var boxFaces = [6][4]int{
+	{0, 2, 3, 1},
+	{4, 5, 7, 6},
+	{0, 1, 5, 4},
+	{2, 6, 7, 3},
+	{0, 4, 6, 2},
+	{1, 3, 7, 5},
+}
+
+func boxCorners(i Line) [8][3]int {
+	var res [8][3]int
+	for c := range res {
+		res[c] = [3]int{i.a.x, i.a.y, i.a.z - 1}
+		if c&1 != 0 {
+			res[c][0] = i.b.x + 1
+		}
+		if c&2 != 0 {
+			res[c][1] = i.b.y + 1
+		}
+		if c&4 != 0 {
+			res[c][2] = i.b.z
+		}
+	}
+	return res
+}
+
+// writeOBJ writes the tower as a Wavefront OBJ mesh with one object per
+// brick. The z axis points up. Bricks are grouped into chosen, toppled, and
+// standing so that viewers can color them separately.
+func writeOBJ(w io.Writer, t Tower, chosen int, falls []bool) {
+	bw := bufio.NewWriter(w)
+	for n, i := range t.Bricks {
+		group := "standing"
+		if n == chosen {
+			group = "chosen"
+		} else if falls != nil && falls[n] {
+			group = "toppled"
+		}
+		fmt.Fprintf(bw, "o brick%d\ng %s\n", n, group)
+		for _, c := range boxCorners(i) {
+			fmt.Fprintf(bw, "v %d %d %d\n", c[0], c[1], c[2])
+		}
+		base := n*8 + 1
+		for _, f := range boxFaces {
+			fmt.Fprintf(bw, "f %d %d %d %d\n", base+f[0], base+f[1], base+f[2], base+f[3])
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// writePLY writes the tower as an ascii PLY mesh with one colored box per
+// brick. The z axis points up.
+func writePLY(w io.Writer, t Tower, chosen int, falls []bool) {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `ply
+format ascii 1.0
+element vertex %d
+property int x
+property int y
+property int z
+property uchar red
+property uchar green
+property uchar blue
+element face %d
+property list uchar int vertex_indices
+end_header
+`, len(t.Bricks)*8, len(t.Bricks)*6)
+	for n, i := range t.Bricks {
+		var color [3]int
+		switch {
+		case n == chosen:
+			color = [3]int{224, 32, 32}
+		case falls != nil && falls[n]:
+			color = [3]int{240, 160, 32}
+		default:
+			// vary the shade so that neighboring bricks are distinguishable
+			shade := 96 + (n*37)%128
+			color = [3]int{shade / 2, shade, shade}
+		}
+		for _, c := range boxCorners(i) {
+			fmt.Fprintf(bw, "%d %d %d %d %d %d\n", c[0], c[1], c[2], color[0], color[1], color[2])
+		}
+	}
+	for n := range t.Bricks {
+		base := n * 8
+		for _, f := range boxFaces {
+			fmt.Fprintf(bw, "4 %d %d %d %d\n", base+f[0], base+f[1], base+f[2], base+f[3])
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		log.Fatalln(err)
+	}
+}
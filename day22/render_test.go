@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const exampleBricks = `1,0,1~1,2,1
+0,0,2~2,0,2
+0,2,3~2,2,3
+0,0,4~0,2,4
+2,0,5~2,2,5
+0,1,6~2,1,6
+1,1,8~1,1,9`
+
+func parseExampleTower(t *testing.T) Tower {
+	t.Helper()
+	var lines []Line
+	for _, i := range strings.Split(exampleBricks, "\n") {
+		var pos [2]Pos
+		for n, j := range strings.Split(i, "~") {
+			var nums [3]int
+			for k, l := range strings.Split(j, ",") {
+				v, err := strconv.Atoi(l)
+				if err != nil {
+					t.Fatal(err)
+				}
+				nums[k] = v
+			}
+			pos[n] = Pos{x: nums[0], y: nums[1], z: nums[2]}
+		}
+		lines = append(lines, Line{
+			a:      pos[0],
+			b:      pos[1],
+			height: pos[1].z - pos[0].z,
+			line:   len(lines) + 1,
+		})
+	}
+	slices.SortFunc(lines, func(a, b Line) int {
+		return posLess(a.a, b.a)
+	})
+	return settleTower(0, 0, 3, 3, lines)
+}
+
+func TestWriteSide(t *testing.T) {
+	tower := parseExampleTower(t)
+	for tcn, tc := range []struct {
+		axis int
+		exp  string
+	}{
+		{
+			axis: 0,
+			exp: ` x
+012
+.G. 6
+.G. 5
+FFF 4
+D.E 3 z
+??? 2
+.A. 1
+--- 0
+`,
+		},
+		{
+			axis: 1,
+			exp: ` y
+012
+.G. 6
+.G. 5
+.F. 4
+??? 3 z
+B.C 2
+AAA 1
+--- 0
+`,
+		},
+	} {
+		tc := tc
+		t.Run("side test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			var b bytes.Buffer
+			writeSide(&b, tower, tc.axis, -1, nil)
+			if v := b.String(); v != tc.exp {
+				t.Fatalf("Invalid output\n%s\n!=\n%s", v, tc.exp)
+			}
+		})
+	}
+}
+
+func TestTopples(t *testing.T) {
+	tower := parseExampleTower(t)
+	for tcn, tc := range []struct {
+		brick string
+		exp   string
+	}{
+		{brick: "A", exp: "BCDEFG"},
+		{brick: "B", exp: ""},
+		{brick: "F", exp: "G"},
+		{brick: "G", exp: ""},
+	} {
+		tc := tc
+		t.Run("topple test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			n := int(tc.brick[0] - 'A')
+			var falls []byte
+			for m, i := range tower.Topples(n) {
+				if i {
+					falls = append(falls, brickLabel(m))
+				}
+			}
+			if v := string(falls); v != tc.exp {
+				t.Fatalf("Invalid output %q != %q", v, tc.exp)
+			}
+		})
+	}
+}
+
+func TestWriteMesh(t *testing.T) {
+	tower := parseExampleTower(t)
+	falls := tower.Topples(0)
+	numBricks := len(tower.Bricks)
+	for tcn, tc := range []struct {
+		write func(b *bytes.Buffer)
+		count func(lines []string) (int, int)
+	}{
+		{
+			write: func(b *bytes.Buffer) {
+				writeOBJ(b, tower, 0, falls)
+			},
+			count: func(lines []string) (int, int) {
+				vertices, faces := 0, 0
+				for _, i := range lines {
+					if strings.HasPrefix(i, "v ") {
+						vertices++
+					} else if strings.HasPrefix(i, "f ") {
+						faces++
+					}
+				}
+				return vertices, faces
+			},
+		},
+		{
+			write: func(b *bytes.Buffer) {
+				writePLY(b, tower, 0, falls)
+			},
+			count: func(lines []string) (int, int) {
+				header := slices.Index(lines, "end_header")
+				if header < 0 {
+					return 0, 0
+				}
+				vertices, faces := 0, 0
+				for _, i := range lines[header+1:] {
+					switch len(strings.Fields(i)) {
+					case 6:
+						vertices++
+					case 5:
+						faces++
+					}
+				}
+				if !slices.Contains(lines, "element vertex "+strconv.Itoa(vertices)) ||
+					!slices.Contains(lines, "element face "+strconv.Itoa(faces)) {
+					return -1, -1
+				}
+				return vertices, faces
+			},
+		},
+	} {
+		tc := tc
+		t.Run("mesh test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			var b bytes.Buffer
+			tc.write(&b)
+			vertices, faces := tc.count(strings.Split(strings.TrimSpace(b.String()), "\n"))
+			if vertices != numBricks*8 {
+				t.Fatalf("Invalid vertex count %d != %d", vertices, numBricks*8)
+			}
+			if faces != numBricks*6 {
+				t.Fatalf("Invalid face count %d != %d", faces, numBricks*6)
+			}
+		})
+	}
+}
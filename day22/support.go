@@ -0,0 +1,161 @@
+package main
+
+import (
+	"math/bits"
+	"slices"
+)
+
+type (
+	// Tower is a settled stack of bricks along with the support graph between
+	// them. Bricks are in settling order, so every brick comes after the
+	// bricks it rests on.
+	Tower struct {
+		Bricks []Line
+		Below  [][]int
+		Above  [][]int
+	}
+)
+
+// settleTower drops every brick in a single pass, recording which bricks
+// each one comes to rest on.
+func settleTower(minx, miny, xwidth, ywidth int, lines []Line) Tower {
+	t := Tower{
+		Bricks: make([]Line, len(lines)),
+		Below:  make([][]int, len(lines)),
+		Above:  make([][]int, len(lines)),
+	}
+	heightMap := make([]int, xwidth*ywidth)
+	topMap := make([]int, xwidth*ywidth)
+	for n := range topMap {
+		topMap[n] = -1
+	}
+	for n, i := range lines {
+		highest := 0
+		for y := i.a.y; y <= i.b.y; y++ {
+			for x := i.a.x; x <= i.b.x; x++ {
+				highest = max(highest, heightMap[posKey(x, y, minx, miny, xwidth)])
+			}
+		}
+		var below []int
+		if highest > 0 {
+			for y := i.a.y; y <= i.b.y; y++ {
+				for x := i.a.x; x <= i.b.x; x++ {
+					key := posKey(x, y, minx, miny, xwidth)
+					if heightMap[key] == highest && !slices.Contains(below, topMap[key]) {
+						below = append(below, topMap[key])
+					}
+				}
+			}
+		}
+		for _, j := range below {
+			t.Above[j] = append(t.Above[j], n)
+		}
+		t.Below[n] = below
+
+		i.a.z = highest + 1
+		i.b.z = i.a.z + i.height
+		t.Bricks[n] = i
+		for y := i.a.y; y <= i.b.y; y++ {
+			for x := i.a.x; x <= i.b.x; x++ {
+				key := posKey(x, y, minx, miny, xwidth)
+				heightMap[key] = i.b.z
+				topMap[key] = n
+			}
+		}
+	}
+	return t
+}
+
+// SafeCount returns the number of bricks that can be removed without any
+// other brick falling, which are those that are not the sole support of any
+// brick.
+func (t Tower) SafeCount() int {
+	count := 0
+	for _, i := range t.Above {
+		safe := true
+		for _, j := range i {
+			if len(t.Below[j]) == 1 {
+				safe = false
+				break
+			}
+		}
+		if safe {
+			count++
+		}
+	}
+	return count
+}
+
+// Dominators returns the immediate dominator of every brick in the support
+// graph rooted at the ground, which is represented by len(t.Bricks). A brick
+// falls when another is removed exactly when the removed brick dominates it.
+// Since bricks are in topological order, the immediate dominator of a brick
+// is the lowest common ancestor in the dominator tree of the bricks it rests
+// on, found here with binary lifting.
+func (t Tower) Dominators() []int {
+	n := len(t.Bricks)
+	ground := n
+	levels := bits.Len(uint(n + 1))
+	up := make([][]int, levels)
+	for k := range up {
+		up[k] = make([]int, n+1)
+		up[k][ground] = ground
+	}
+	depth := make([]int, n+1)
+
+	lca := func(a, b int) int {
+		if depth[a] < depth[b] {
+			a, b = b, a
+		}
+		for k := levels - 1; k >= 0; k-- {
+			if depth[a]-(1<<k) >= depth[b] {
+				a = up[k][a]
+			}
+		}
+		if a == b {
+			return a
+		}
+		for k := levels - 1; k >= 0; k-- {
+			if up[k][a] != up[k][b] {
+				a = up[k][a]
+				b = up[k][b]
+			}
+		}
+		return up[0][a]
+	}
+
+	idom := make([]int, n)
+	for v, below := range t.Below {
+		d := ground
+		if len(below) > 0 {
+			d = below[0]
+			for _, i := range below[1:] {
+				d = lca(d, i)
+			}
+		}
+		idom[v] = d
+		depth[v] = depth[d] + 1
+		up[0][v] = d
+		for k := 1; k < levels; k++ {
+			up[k][v] = up[k-1][up[k-1][v]]
+		}
+	}
+	return idom
+}
+
+// ChainReactions returns for every brick the number of other bricks that
+// would fall if it were removed, which is the size of its dominator subtree
+// excluding itself.
+func (t Tower) ChainReactions() []int {
+	idom := t.Dominators()
+	size := make([]int, len(t.Bricks)+1)
+	for v := len(t.Bricks) - 1; v >= 0; v-- {
+		size[v]++
+		size[idom[v]] += size[v]
+	}
+	res := make([]int, len(t.Bricks))
+	for v := range res {
+		res[v] = size[v] - 1
+	}
+	return res
+}
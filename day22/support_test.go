@@ -0,0 +1,86 @@
+package main
+
+import (
+	"math/rand"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func genBricks(r *rand.Rand, n, width, depth int) []Line {
+	lines := make([]Line, 0, n)
+	for i := 0; i < n; i++ {
+		a := Pos{
+			x: r.Intn(width),
+			y: r.Intn(width),
+			z: r.Intn(depth) + 1,
+		}
+		b := a
+		l := r.Intn(4)
+		switch r.Intn(3) {
+		case 0:
+			b.x = min(a.x+l, width-1)
+		case 1:
+			b.y = min(a.y+l, width-1)
+		case 2:
+			b.z = a.z + l
+		}
+		lines = append(lines, Line{
+			a:      a,
+			b:      b,
+			height: b.z - a.z,
+		})
+	}
+	slices.SortFunc(lines, func(a, b Line) int {
+		return posLess(a.a, b.a)
+	})
+	return lines
+}
+
+func TestTower(t *testing.T) {
+	r := rand.New(rand.NewSource(22))
+	for tcn := 0; tcn < 64; tcn++ {
+		const width = 6
+		lines := genBricks(r, 4+r.Intn(60), width, 40)
+		t.Run("tower test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			tower := settleTower(0, 0, width, width, lines)
+
+			heightMap := make([]int, width*width)
+			fullTower := make([]Line, len(lines))
+			getTower(0, 0, width, heightMap, lines, -1, fullTower)
+			for n, i := range tower.Bricks {
+				if i.a != fullTower[n].a || i.b != fullTower[n].b {
+					t.Fatalf("Invalid settled brick %d %v != %v", n, i, fullTower[n])
+				}
+			}
+
+			chain := tower.ChainReactions()
+			candidate := make([]Line, len(lines))
+			count := 0
+			for n := range lines {
+				getTower(0, 0, width, heightMap, lines, n, candidate)
+				delta := towerDelta(fullTower, candidate, n)
+				if delta == 0 {
+					count++
+				}
+				if chain[n] != delta {
+					t.Fatalf("Invalid chain reaction for brick %d %d != %d", n, chain[n], delta)
+				}
+			}
+			if v := tower.SafeCount(); v != count {
+				t.Fatalf("Invalid safe count %d != %d", v, count)
+			}
+		})
+	}
+}
+
+func BenchmarkTower(b *testing.B) {
+	const width = 100
+	lines := genBricks(rand.New(rand.NewSource(22)), 50000, width, 100000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tower := settleTower(0, 0, width, width, lines)
+		tower.SafeCount()
+		tower.ChainReactions()
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"log"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	maxDenseNodes = 64
+	// splitDepth is the number of branch levels expanded before the search is
+	// handed out to workers
+	splitDepth = 8
+)
+
+type (
+	DenseEdge struct {
+		to   int
+		cost int
+	}
+
+	// DenseGraph is a contracted graph whose junctions are renumbered to
+	// dense ids so that a visited set fits in a uint64.
+	DenseGraph struct {
+		ids     []int
+		adj     [][4]DenseEdge
+		deg     []int
+		start   int
+		end     int
+		endMask uint64
+	}
+
+	denseTask struct {
+		id      int
+		visited uint64
+		g       int
+		path    []int
+	}
+
+	denseSearch struct {
+		g        *DenseGraph
+		path     []int
+		best     int
+		bestPath []int
+	}
+)
+
+func NewDenseGraph(start, end int, graph map[int]map[int]int) *DenseGraph {
+	g := &DenseGraph{}
+	denseIDs := map[int]int{}
+	denseID := func(k int) int {
+		if v, ok := denseIDs[k]; ok {
+			return v
+		}
+		v := len(g.ids)
+		if v >= maxDenseNodes {
+			log.Fatalln("Too many junctions")
+		}
+		denseIDs[k] = v
+		g.ids = append(g.ids, k)
+		g.adj = append(g.adj, [4]DenseEdge{})
+		g.deg = append(g.deg, 0)
+		return v
+	}
+	g.start = denseID(start)
+	g.end = denseID(end)
+	// visit junctions breadth first in key order so that dense ids are stable
+	// across runs
+	openSet := NewRing[int](len(graph))
+	openSet.Write(start)
+	for {
+		cur, ok := openSet.Read()
+		if !ok {
+			break
+		}
+		u := denseIDs[cur]
+		for _, k := range sortedKeys(graph[cur]) {
+			if _, ok := denseIDs[k]; !ok {
+				openSet.Write(k)
+			}
+			v := denseID(k)
+			if g.deg[u] >= len(g.adj[u]) {
+				log.Fatalln("Junction has too many edges")
+			}
+			g.adj[u][g.deg[u]] = DenseEdge{
+				to:   v,
+				cost: graph[cur][k],
+			}
+			g.deg[u]++
+			if v == g.end {
+				g.endMask |= 1 << u
+			}
+		}
+	}
+	return g
+}
+
+// Search returns the length of the longest path from start to end that
+// visits each junction at most once along with the junctions of the path, or
+// -1 if there is none. The first branch levels are expanded serially and the
+// resulting subtrees are searched in parallel.
+func (g *DenseGraph) Search() (int, []int) {
+	var tasks []denseTask
+	g.expand(g.start, 1<<g.start, 0, []int{g.start}, &tasks)
+
+	numWorkers := min(runtime.GOMAXPROCS(0), len(tasks))
+	results := make([]denseSearch, numWorkers)
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(s *denseSearch) {
+			defer wg.Done()
+			s.g = g
+			s.best = -1
+			for {
+				idx := int(next.Add(1)) - 1
+				if idx >= len(tasks) {
+					break
+				}
+				t := tasks[idx]
+				s.path = append(s.path[:0], t.path...)
+				s.search(t.id, t.visited, t.g)
+			}
+		}(&results[i])
+	}
+	wg.Wait()
+
+	best := -1
+	var bestPath []int
+	for _, i := range results {
+		if i.best > best {
+			best = i.best
+			bestPath = i.bestPath
+		}
+	}
+	route := make([]int, len(bestPath))
+	for n, i := range bestPath {
+		route[n] = g.ids[i]
+	}
+	return best, route
+}
+
+func (g *DenseGraph) expand(cur int, visited uint64, dist int, path []int, tasks *[]denseTask) {
+	if len(path) > splitDepth || cur == g.end {
+		*tasks = append(*tasks, denseTask{
+			id:      cur,
+			visited: visited,
+			g:       dist,
+			path:    slices.Clone(path),
+		})
+		return
+	}
+	for _, e := range g.adj[cur][:g.deg[cur]] {
+		if visited&(1<<e.to) != 0 || g.isCutOff(e.to, visited|1<<e.to) {
+			continue
+		}
+		g.expand(e.to, visited|1<<e.to, dist+e.cost, append(path, e.to), tasks)
+	}
+}
+
+// isCutOff reports whether the end can no longer be reached after moving to
+// cur. Once every junction leading into the end has been visited, the only
+// way to finish is from cur itself, which generalizes the rule that the last
+// junction before the exit must go straight to the exit.
+func (g *DenseGraph) isCutOff(cur int, visited uint64) bool {
+	if cur == g.end || g.endMask&(1<<cur) != 0 {
+		return false
+	}
+	return g.endMask&^visited == 0
+}
+
+func (s *denseSearch) search(cur int, visited uint64, dist int) {
+	if cur == s.g.end {
+		if dist > s.best {
+			s.best = dist
+			s.bestPath = append(s.bestPath[:0], s.path...)
+		}
+		return
+	}
+	for _, e := range s.g.adj[cur][:s.g.deg[cur]] {
+		bit := uint64(1) << e.to
+		if visited&bit != 0 || s.g.isCutOff(e.to, visited|bit) {
+			continue
+		}
+		s.path = append(s.path, e.to)
+		s.search(e.to, visited|bit, dist+e.cost)
+		s.path = s.path[:len(s.path)-1]
+	}
+}
+
+func sortedKeys(m map[int]int) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
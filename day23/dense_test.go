@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+type (
+	trailGraphs struct {
+		width, height    int
+		start, end       int
+		prunedCost       int
+		undirected       map[int]map[int]int
+		directed         map[int]map[int]int
+		grid             [][]byte
+		startPos, endPos Pos
+	}
+)
+
+func readTrailGraphs(tb testing.TB) trailGraphs {
+	tb.Helper()
+	b, err := os.ReadFile(puzzleInput)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	grid := bytes.Split(bytes.TrimSpace(b), []byte("\n"))
+	height := len(grid)
+	width := len(grid[0])
+	start, end := findStartEnd(grid, width, height)
+	undirected, directed := contractPaths(start, end, grid, width, height)
+	startID, startCost := findBranch(start.y*width+start.x, undirected)
+	endID, endCost := findBranch(end.y*width+end.x, undirected)
+	return trailGraphs{
+		width:      width,
+		height:     height,
+		start:      startID,
+		end:        endID,
+		prunedCost: startCost + endCost,
+		undirected: undirected,
+		directed:   directed,
+		grid:       grid,
+		startPos:   start,
+		endPos:     end,
+	}
+}
+
+func TestDenseGraph(t *testing.T) {
+	g := readTrailGraphs(t)
+	for _, tc := range []struct {
+		name  string
+		graph map[int]map[int]int
+	}{
+		{
+			name:  "directed",
+			graph: g.directed,
+		},
+		{
+			name:  "undirected",
+			graph: g.undirected,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			exp := searchLongestGraph(g.start, g.end, tc.graph, g.width, g.height)
+			if v, _ := NewDenseGraph(g.start, g.end, tc.graph).Search(); v != exp {
+				t.Fatalf("Invalid output %d != %d", v, exp)
+			}
+		})
+	}
+}
+
+func TestExpandRoute(t *testing.T) {
+	g := readTrailGraphs(t)
+	for _, tc := range []struct {
+		name     string
+		graph    map[int]map[int]int
+		directed bool
+	}{
+		{
+			name:     "directed",
+			graph:    g.directed,
+			directed: true,
+		},
+		{
+			name:     "undirected",
+			graph:    g.undirected,
+			directed: false,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			best, route := NewDenseGraph(g.start, g.end, tc.graph).Search()
+			path := expandRoute(route, tc.graph, g.startPos, g.endPos, g.grid, g.width, g.height, tc.directed)
+			if len(path)-1 != g.prunedCost+best {
+				t.Fatalf("Invalid route length %d != %d", len(path)-1, g.prunedCost+best)
+			}
+			if path[0] != g.startPos || path[len(path)-1] != g.endPos {
+				t.Fatalf("Route does not go from start to end")
+			}
+			seen := map[Pos]struct{}{}
+			for n, i := range path {
+				if _, ok := seen[i]; ok {
+					t.Fatalf("Route revisits %v", i)
+				}
+				seen[i] = struct{}{}
+				if g.grid[i.y][i.x] == '#' {
+					t.Fatalf("Route enters forest at %v", i)
+				}
+				if n == 0 {
+					continue
+				}
+				prev := path[n-1]
+				if abs(i.x-prev.x)+abs(i.y-prev.y) != 1 {
+					t.Fatalf("Route is not contiguous at %v", i)
+				}
+			}
+		})
+	}
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+func BenchmarkSearchLongestGraph(b *testing.B) {
+	g := readTrailGraphs(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		searchLongestGraph(g.start, g.end, g.undirected, g.width, g.height)
+	}
+}
+
+func BenchmarkDenseGraph(b *testing.B) {
+	g := readTrailGraphs(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewDenseGraph(g.start, g.end, g.undirected).Search()
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+)
+
+type (
+	// TrailGraph is an inspectable form of the contracted trail graphs.
+	TrailGraph struct {
+		Width       int              `json:"width"`
+		Height      int              `json:"height"`
+		Start       int              `json:"start"`
+		End         int              `json:"end"`
+		SearchStart int              `json:"search_start"`
+		SearchEnd   int              `json:"search_end"`
+		Nodes       []TrailNode      `json:"nodes"`
+		Undirected  []TrailGraphEdge `json:"undirected"`
+		Directed    []TrailGraphEdge `json:"directed"`
+	}
+
+	TrailNode struct {
+		ID int `json:"id"`
+		X  int `json:"x"`
+		Y  int `json:"y"`
+	}
+
+	// TrailGraphEdge is an edge of length Length. Undirected edges are listed
+	// once with From < To, and Slope records which way the slopes along the
+	// trail permit it to be walked: forward, reverse, both, or none.
+	TrailGraphEdge struct {
+		From   int    `json:"from"`
+		To     int    `json:"to"`
+		Length int    `json:"length"`
+		Slope  string `json:"slope,omitempty"`
+	}
+)
+
+func NewTrailGraph(start, end Pos, undirected, directed map[int]map[int]int, w, h int) TrailGraph {
+	startID := start.y*w + start.x
+	endID := end.y*w + end.x
+	searchStart, _ := findBranch(startID, undirected)
+	searchEnd, _ := findBranch(endID, undirected)
+	g := TrailGraph{
+		Width:       w,
+		Height:      h,
+		Start:       startID,
+		End:         endID,
+		SearchStart: searchStart,
+		SearchEnd:   searchEnd,
+	}
+
+	nodeSet := map[int]struct{}{}
+	for k, v := range undirected {
+		nodeSet[k] = struct{}{}
+		for i := range v {
+			nodeSet[i] = struct{}{}
+		}
+	}
+	nodes := make([]int, 0, len(nodeSet))
+	for k := range nodeSet {
+		nodes = append(nodes, k)
+	}
+	slices.Sort(nodes)
+	for _, k := range nodes {
+		g.Nodes = append(g.Nodes, TrailNode{
+			ID: k,
+			X:  k % w,
+			Y:  k / w,
+		})
+	}
+
+	for _, n := range g.Nodes {
+		for _, k := range sortedKeys(undirected[n.ID]) {
+			if k < n.ID {
+				continue
+			}
+			_, forward := directed[n.ID][k]
+			_, reverse := directed[k][n.ID]
+			slope := "none"
+			switch {
+			case forward && reverse:
+				slope = "both"
+			case forward:
+				slope = "forward"
+			case reverse:
+				slope = "reverse"
+			}
+			g.Undirected = append(g.Undirected, TrailGraphEdge{
+				From:   n.ID,
+				To:     k,
+				Length: undirected[n.ID][k],
+				Slope:  slope,
+			})
+		}
+		for _, k := range sortedKeys(directed[n.ID]) {
+			g.Directed = append(g.Directed, TrailGraphEdge{
+				From:   n.ID,
+				To:     k,
+				Length: directed[n.ID][k],
+			})
+		}
+	}
+	return g
+}
+
+func (g TrailGraph) WriteJSON(w io.Writer) {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(g); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// WriteDOT writes the undirected and directed graphs as two graphviz graphs.
+// Nodes are pinned to their grid coordinates for use with neato -n.
+func (g TrailGraph) WriteDOT(w io.Writer) {
+	bw := bufio.NewWriter(w)
+	for _, i := range []struct {
+		kind  string
+		name  string
+		op    string
+		edges []TrailGraphEdge
+	}{
+		{
+			kind:  "graph",
+			name:  "undirected",
+			op:    "--",
+			edges: g.Undirected,
+		},
+		{
+			kind:  "digraph",
+			name:  "directed",
+			op:    "->",
+			edges: g.Directed,
+		},
+	} {
+		fmt.Fprintf(bw, "%s %s {\n", i.kind, i.name)
+		for _, n := range g.Nodes {
+			attrs := ""
+			switch n.ID {
+			case g.Start, g.End:
+				attrs = " shape=doublecircle"
+			case g.SearchStart, g.SearchEnd:
+				attrs = " style=filled"
+			}
+			fmt.Fprintf(bw, "\tn%d [label=\"%d,%d\" pos=\"%d,%d!\"%s];\n", n.ID, n.X, n.Y, n.X*8, -n.Y*8, attrs)
+		}
+		for _, e := range i.edges {
+			attrs := ""
+			switch e.Slope {
+			case "forward":
+				attrs = " dir=forward"
+			case "reverse":
+				attrs = " dir=back"
+			case "none":
+				attrs = " style=dashed"
+			}
+			fmt.Fprintf(bw, "\tn%d %s n%d [label=\"%d\"%s];\n", e.From, i.op, e.To, e.Length, attrs)
+		}
+		fmt.Fprintln(bw, "}")
+	}
+	if err := bw.Flush(); err != nil {
+		log.Fatalln(err)
+	}
+}
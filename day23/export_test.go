@@ -0,0 +1,531 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+const exampleMap = `#.#####################
+#.......#########...###
+#######.#########.#.###
+###.....#.>.>.###.#.###
+###v#####.#v#.###.#.###
+###.>...#.#.#.....#...#
+###v###.#.#.#########.#
+###...#.#.#.......#...#
+#####.#.#.#######.#.###
+#.....#.#.#.......#...#
+#.#####.#.#.#########v#
+#.#...#...#...###...>.#
+#.#.#v#######v###.###v#
+#...#.>.#...>.>.#.###.#
+#####v#.#.###v#.#.###.#
+#.....#...#...#.#.#...#
+#.#########.###.#.#.###
+#...###...#...#...#.###
+###.###.#.###v#####v###
+#...#...#.#.>.>.#.>.###
+#.###.###.#.###.#.#v###
+#.....###...###...#...#
+#####################.#`
+
+// exampleTrailGraph is the contracted graph of the example map.
+func exampleTrailGraph() TrailGraph {
+	grid := bytes.Split([]byte(exampleMap), []byte("\n"))
+	height := len(grid)
+	width := len(grid[0])
+	start, end := findStartEnd(grid, width, height)
+	undirected, directed := contractPaths(start, end, grid, width, height)
+	return NewTrailGraph(start, end, undirected, directed, width, height)
+}
+
+// slopeTrailGraph is a 3x2 grid whose four trails are walkable forward,
+// reverse, both ways, and not at all.
+func slopeTrailGraph() TrailGraph {
+	return NewTrailGraph(Pos{x: 0, y: 0}, Pos{x: 2, y: 1}, map[int]map[int]int{
+		0: {2: 2, 3: 1},
+		2: {0: 2, 3: 3},
+		3: {0: 1, 2: 3, 5: 2},
+		5: {3: 2},
+	}, map[int]map[int]int{
+		0: {2: 2},
+		2: {3: 3},
+		3: {0: 1, 2: 3},
+	}, 3, 2)
+}
+
+const exampleTrailDOT = `graph undirected {
+	n1 [label="1,0" pos="8,0!" shape=doublecircle];
+	n80 [label="11,3" pos="88,-24!"];
+	n118 [label="3,5" pos="24,-40!" style=filled];
+	n274 [label="21,11" pos="168,-88!"];
+	n304 [label="5,13" pos="40,-104!"];
+	n312 [label="13,13" pos="104,-104!"];
+	n450 [label="13,19" pos="104,-152!"];
+	n456 [label="19,19" pos="152,-152!" style=filled];
+	n527 [label="21,22" pos="168,-176!" shape=doublecircle];
+	n1 -- n118 [label="15" dir=forward];
+	n80 -- n118 [label="22" dir=back];
+	n80 -- n274 [label="30" dir=forward];
+	n80 -- n312 [label="24" dir=forward];
+	n118 -- n304 [label="22" dir=forward];
+	n274 -- n312 [label="18" dir=back];
+	n274 -- n456 [label="10" dir=forward];
+	n304 -- n312 [label="12" dir=forward];
+	n304 -- n450 [label="38" dir=forward];
+	n312 -- n450 [label="10" dir=forward];
+	n450 -- n456 [label="10" dir=forward];
+	n456 -- n527 [label="5" dir=forward];
+}
+digraph directed {
+	n1 [label="1,0" pos="8,0!" shape=doublecircle];
+	n80 [label="11,3" pos="88,-24!"];
+	n118 [label="3,5" pos="24,-40!" style=filled];
+	n274 [label="21,11" pos="168,-88!"];
+	n304 [label="5,13" pos="40,-104!"];
+	n312 [label="13,13" pos="104,-104!"];
+	n450 [label="13,19" pos="104,-152!"];
+	n456 [label="19,19" pos="152,-152!" style=filled];
+	n527 [label="21,22" pos="168,-176!" shape=doublecircle];
+	n1 -> n118 [label="15"];
+	n80 -> n274 [label="30"];
+	n80 -> n312 [label="24"];
+	n118 -> n80 [label="22"];
+	n118 -> n304 [label="22"];
+	n274 -> n456 [label="10"];
+	n304 -> n312 [label="12"];
+	n304 -> n450 [label="38"];
+	n312 -> n274 [label="18"];
+	n312 -> n450 [label="10"];
+	n450 -> n456 [label="10"];
+	n456 -> n527 [label="5"];
+}
+`
+
+const exampleTrailJSON = `{
+  "width": 23,
+  "height": 23,
+  "start": 1,
+  "end": 527,
+  "search_start": 118,
+  "search_end": 456,
+  "nodes": [
+    {
+      "id": 1,
+      "x": 1,
+      "y": 0
+    },
+    {
+      "id": 80,
+      "x": 11,
+      "y": 3
+    },
+    {
+      "id": 118,
+      "x": 3,
+      "y": 5
+    },
+    {
+      "id": 274,
+      "x": 21,
+      "y": 11
+    },
+    {
+      "id": 304,
+      "x": 5,
+      "y": 13
+    },
+    {
+      "id": 312,
+      "x": 13,
+      "y": 13
+    },
+    {
+      "id": 450,
+      "x": 13,
+      "y": 19
+    },
+    {
+      "id": 456,
+      "x": 19,
+      "y": 19
+    },
+    {
+      "id": 527,
+      "x": 21,
+      "y": 22
+    }
+  ],
+  "undirected": [
+    {
+      "from": 1,
+      "to": 118,
+      "length": 15,
+      "slope": "forward"
+    },
+    {
+      "from": 80,
+      "to": 118,
+      "length": 22,
+      "slope": "reverse"
+    },
+    {
+      "from": 80,
+      "to": 274,
+      "length": 30,
+      "slope": "forward"
+    },
+    {
+      "from": 80,
+      "to": 312,
+      "length": 24,
+      "slope": "forward"
+    },
+    {
+      "from": 118,
+      "to": 304,
+      "length": 22,
+      "slope": "forward"
+    },
+    {
+      "from": 274,
+      "to": 312,
+      "length": 18,
+      "slope": "reverse"
+    },
+    {
+      "from": 274,
+      "to": 456,
+      "length": 10,
+      "slope": "forward"
+    },
+    {
+      "from": 304,
+      "to": 312,
+      "length": 12,
+      "slope": "forward"
+    },
+    {
+      "from": 304,
+      "to": 450,
+      "length": 38,
+      "slope": "forward"
+    },
+    {
+      "from": 312,
+      "to": 450,
+      "length": 10,
+      "slope": "forward"
+    },
+    {
+      "from": 450,
+      "to": 456,
+      "length": 10,
+      "slope": "forward"
+    },
+    {
+      "from": 456,
+      "to": 527,
+      "length": 5,
+      "slope": "forward"
+    }
+  ],
+  "directed": [
+    {
+      "from": 1,
+      "to": 118,
+      "length": 15
+    },
+    {
+      "from": 80,
+      "to": 274,
+      "length": 30
+    },
+    {
+      "from": 80,
+      "to": 312,
+      "length": 24
+    },
+    {
+      "from": 118,
+      "to": 80,
+      "length": 22
+    },
+    {
+      "from": 118,
+      "to": 304,
+      "length": 22
+    },
+    {
+      "from": 274,
+      "to": 456,
+      "length": 10
+    },
+    {
+      "from": 304,
+      "to": 312,
+      "length": 12
+    },
+    {
+      "from": 304,
+      "to": 450,
+      "length": 38
+    },
+    {
+      "from": 312,
+      "to": 274,
+      "length": 18
+    },
+    {
+      "from": 312,
+      "to": 450,
+      "length": 10
+    },
+    {
+      "from": 450,
+      "to": 456,
+      "length": 10
+    },
+    {
+      "from": 456,
+      "to": 527,
+      "length": 5
+    }
+  ]
+}
+`
+
+const slopeTrailDOT = `graph undirected {
+	n0 [label="0,0" pos="0,0!" shape=doublecircle];
+	n2 [label="2,0" pos="16,0!"];
+	n3 [label="0,1" pos="0,-8!" style=filled];
+	n5 [label="2,1" pos="16,-8!" shape=doublecircle];
+	n0 -- n2 [label="2" dir=forward];
+	n0 -- n3 [label="1" dir=back];
+	n2 -- n3 [label="3"];
+	n3 -- n5 [label="2" style=dashed];
+}
+digraph directed {
+	n0 [label="0,0" pos="0,0!" shape=doublecircle];
+	n2 [label="2,0" pos="16,0!"];
+	n3 [label="0,1" pos="0,-8!" style=filled];
+	n5 [label="2,1" pos="16,-8!" shape=doublecircle];
+	n0 -> n2 [label="2"];
+	n2 -> n3 [label="3"];
+	n3 -> n0 [label="1"];
+	n3 -> n2 [label="3"];
+}
+`
+
+const slopeTrailJSON = `{
+  "width": 3,
+  "height": 2,
+  "start": 0,
+  "end": 5,
+  "search_start": 0,
+  "search_end": 3,
+  "nodes": [
+    {
+      "id": 0,
+      "x": 0,
+      "y": 0
+    },
+    {
+      "id": 2,
+      "x": 2,
+      "y": 0
+    },
+    {
+      "id": 3,
+      "x": 0,
+      "y": 1
+    },
+    {
+      "id": 5,
+      "x": 2,
+      "y": 1
+    }
+  ],
+  "undirected": [
+    {
+      "from": 0,
+      "to": 2,
+      "length": 2,
+      "slope": "forward"
+    },
+    {
+      "from": 0,
+      "to": 3,
+      "length": 1,
+      "slope": "reverse"
+    },
+    {
+      "from": 2,
+      "to": 3,
+      "length": 3,
+      "slope": "both"
+    },
+    {
+      "from": 3,
+      "to": 5,
+      "length": 2,
+      "slope": "none"
+    }
+  ],
+  "directed": [
+    {
+      "from": 0,
+      "to": 2,
+      "length": 2
+    },
+    {
+      "from": 2,
+      "to": 3,
+      "length": 3
+    },
+    {
+      "from": 3,
+      "to": 0,
+      "length": 1
+    },
+    {
+      "from": 3,
+      "to": 2,
+      "length": 3
+    }
+  ]
+}
+`
+
+func TestTrailGraph(t *testing.T) {
+	for tcn, tc := range []struct {
+		graph      TrailGraph
+		ends       [4]int
+		nodes      []TrailNode
+		undirected []TrailGraphEdge
+		directed   []TrailGraphEdge
+	}{
+		{
+			graph: exampleTrailGraph(),
+			ends:  [4]int{1, 527, 118, 456},
+			nodes: []TrailNode{
+				{ID: 1, X: 1, Y: 0},
+				{ID: 80, X: 11, Y: 3},
+				{ID: 118, X: 3, Y: 5},
+				{ID: 274, X: 21, Y: 11},
+				{ID: 304, X: 5, Y: 13},
+				{ID: 312, X: 13, Y: 13},
+				{ID: 450, X: 13, Y: 19},
+				{ID: 456, X: 19, Y: 19},
+				{ID: 527, X: 21, Y: 22},
+			},
+			undirected: []TrailGraphEdge{
+				{From: 1, To: 118, Length: 15, Slope: "forward"},
+				{From: 80, To: 118, Length: 22, Slope: "reverse"},
+				{From: 80, To: 274, Length: 30, Slope: "forward"},
+				{From: 80, To: 312, Length: 24, Slope: "forward"},
+				{From: 118, To: 304, Length: 22, Slope: "forward"},
+				{From: 274, To: 312, Length: 18, Slope: "reverse"},
+				{From: 274, To: 456, Length: 10, Slope: "forward"},
+				{From: 304, To: 312, Length: 12, Slope: "forward"},
+				{From: 304, To: 450, Length: 38, Slope: "forward"},
+				{From: 312, To: 450, Length: 10, Slope: "forward"},
+				{From: 450, To: 456, Length: 10, Slope: "forward"},
+				{From: 456, To: 527, Length: 5, Slope: "forward"},
+			},
+			directed: []TrailGraphEdge{
+				{From: 1, To: 118, Length: 15},
+				{From: 80, To: 274, Length: 30},
+				{From: 80, To: 312, Length: 24},
+				{From: 118, To: 80, Length: 22},
+				{From: 118, To: 304, Length: 22},
+				{From: 274, To: 456, Length: 10},
+				{From: 304, To: 312, Length: 12},
+				{From: 304, To: 450, Length: 38},
+				{From: 312, To: 274, Length: 18},
+				{From: 312, To: 450, Length: 10},
+				{From: 450, To: 456, Length: 10},
+				{From: 456, To: 527, Length: 5},
+			},
+		},
+		{
+			graph: slopeTrailGraph(),
+			ends:  [4]int{0, 5, 0, 3},
+			nodes: []TrailNode{
+				{ID: 0, X: 0, Y: 0},
+				{ID: 2, X: 2, Y: 0},
+				{ID: 3, X: 0, Y: 1},
+				{ID: 5, X: 2, Y: 1},
+			},
+			undirected: []TrailGraphEdge{
+				{From: 0, To: 2, Length: 2, Slope: "forward"},
+				{From: 0, To: 3, Length: 1, Slope: "reverse"},
+				{From: 2, To: 3, Length: 3, Slope: "both"},
+				{From: 3, To: 5, Length: 2, Slope: "none"},
+			},
+			directed: []TrailGraphEdge{
+				{From: 0, To: 2, Length: 2},
+				{From: 2, To: 3, Length: 3},
+				{From: 3, To: 0, Length: 1},
+				{From: 3, To: 2, Length: 3},
+			},
+		},
+	} {
+		tc := tc
+		t.Run("trail graph test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			g := tc.graph
+			if v := [4]int{g.Start, g.End, g.SearchStart, g.SearchEnd}; v != tc.ends {
+				t.Fatalf("Invalid ends %v != %v", v, tc.ends)
+			}
+			if !reflect.DeepEqual(g.Nodes, tc.nodes) {
+				t.Fatalf("Invalid nodes %v != %v", g.Nodes, tc.nodes)
+			}
+			if !reflect.DeepEqual(g.Undirected, tc.undirected) {
+				t.Fatalf("Invalid undirected edges %v != %v", g.Undirected, tc.undirected)
+			}
+			if !reflect.DeepEqual(g.Directed, tc.directed) {
+				t.Fatalf("Invalid directed edges %v != %v", g.Directed, tc.directed)
+			}
+		})
+	}
+}
+
+func TestTrailGraphWrite(t *testing.T) {
+	for tcn, tc := range []struct {
+		graph TrailGraph
+		write func(g TrailGraph, b *bytes.Buffer)
+		exp   string
+	}{
+		{
+			graph: exampleTrailGraph(),
+			write: func(g TrailGraph, b *bytes.Buffer) { g.WriteDOT(b) },
+			exp:   exampleTrailDOT,
+		},
+		{
+			graph: slopeTrailGraph(),
+			write: func(g TrailGraph, b *bytes.Buffer) { g.WriteDOT(b) },
+			exp:   slopeTrailDOT,
+		},
+		{
+			graph: exampleTrailGraph(),
+			write: func(g TrailGraph, b *bytes.Buffer) { g.WriteJSON(b) },
+			exp:   exampleTrailJSON,
+		},
+		{
+			graph: slopeTrailGraph(),
+			write: func(g TrailGraph, b *bytes.Buffer) { g.WriteJSON(b) },
+			exp:   slopeTrailJSON,
+		},
+	} {
+		tc := tc
+		t.Run("trail graph write test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			var b bytes.Buffer
+			tc.write(tc.graph, &b)
+			if v := b.String(); v != tc.exp {
+				t.Fatalf("Invalid output\n%s\n!=\n%s", v, tc.exp)
+			}
+		})
+	}
+}
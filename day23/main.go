@@ -3,6 +3,7 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -13,6 +14,12 @@ const (
 )
 
 func main() {
+	part := flag.Int("part", 2, "part whose route is rendered")
+	render := flag.Bool("render", false, "print the longest route over the map")
+	pngName := flag.String("png", "", "write the longest route over the map to a png file")
+	export := flag.String("export", "", "write the contracted graphs to stdout as dot or json instead of solving")
+	flag.Parse()
+
 	file, err := os.Open(puzzleInput)
 	if err != nil {
 		log.Fatalln(err)
@@ -34,6 +41,51 @@ func main() {
 
 	height := len(grid)
 	width := len(grid[0])
+	start, end := findStartEnd(grid, width, height)
+
+	undirectedGraph, directedGraph := contractPaths(start, end, grid, width, height)
+	switch *export {
+	case "":
+	case "dot":
+		NewTrailGraph(start, end, undirectedGraph, directedGraph, width, height).WriteDOT(os.Stdout)
+		return
+	case "json":
+		NewTrailGraph(start, end, undirectedGraph, directedGraph, width, height).WriteJSON(os.Stdout)
+		return
+	default:
+		log.Fatalln("Invalid export format")
+	}
+	startID := start.y*width + start.x
+	endID := end.y*width + end.x
+	startID, startCost := findBranch(startID, undirectedGraph)
+	endID, endCost := findBranch(endID, undirectedGraph)
+	prunedCost := startCost + endCost
+	part1, route1 := NewDenseGraph(startID, endID, directedGraph).Search()
+	fmt.Println("Part 1:", prunedCost+part1)
+	part2, route2 := NewDenseGraph(startID, endID, undirectedGraph).Search()
+	fmt.Println("Part 2:", prunedCost+part2)
+
+	if !*render && *pngName == "" {
+		return
+	}
+	var route []Pos
+	switch *part {
+	case 1:
+		route = expandRoute(route1, directedGraph, start, end, grid, width, height, true)
+	case 2:
+		route = expandRoute(route2, undirectedGraph, start, end, grid, width, height, false)
+	default:
+		log.Fatalln("Invalid part")
+	}
+	if *render {
+		renderRoute(os.Stdout, grid, route)
+	}
+	if *pngName != "" {
+		writeRoutePNG(*pngName, grid, route)
+	}
+}
+
+func findStartEnd(grid [][]byte, width, height int) (Pos, Pos) {
 	start := Pos{
 		x: 0,
 		y: 0,
@@ -50,15 +102,7 @@ func main() {
 	if end.x < 0 {
 		log.Fatalln("No end")
 	}
-
-	undirectedGraph, directedGraph := contractPaths(start, end, grid, width, height)
-	startID := start.y*width + start.x
-	endID := end.y*width + end.x
-	startID, startCost := findBranch(startID, undirectedGraph)
-	endID, endCost := findBranch(endID, undirectedGraph)
-	prunedCost := startCost + endCost
-	fmt.Println("Part 1:", prunedCost+searchLongestGraph(startID, endID, directedGraph, width, height))
-	fmt.Println("Part 2:", prunedCost+searchLongestGraph(startID, endID, undirectedGraph, width, height))
+	return start, end
 }
 
 func searchLongestGraph(start, end int, graph map[int]map[int]int, w, h int) int {
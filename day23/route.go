@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"log"
+	"os"
+)
+
+const (
+	routeCellSize = 4
+)
+
+// expandRoute expands a junction sequence returned by a graph search into the
+// grid cells of the full trail from start to end.
+func expandRoute(route []int, graph map[int]map[int]int, start, end Pos, grid [][]byte, w, h int, directed bool) []Pos {
+	if len(route) == 0 {
+		return nil
+	}
+	toPos := func(id int) Pos {
+		return Pos{
+			x: id % w,
+			y: id / w,
+		}
+	}
+	path := []Pos{start}
+	if first := toPos(route[0]); first != start {
+		path = append(path, tracePath(start, first, -1, grid, w, h, directed)...)
+	}
+	for n, i := range route[1:] {
+		prev := route[n]
+		cost, ok := graph[prev][i]
+		if !ok {
+			log.Fatalln("Route edge not in graph")
+		}
+		path = append(path, tracePath(toPos(prev), toPos(i), cost, grid, w, h, directed)...)
+	}
+	if last := toPos(route[len(route)-1]); last != end {
+		path = append(path, tracePath(last, end, -1, grid, w, h, directed)...)
+	}
+	return path
+}
+
+// tracePath walks the corridors leaving from until one of them arrives at to
+// after exactly cost steps, or any number of steps if cost is negative, and
+// returns the cells walked excluding from.
+func tracePath(from, to Pos, cost int, grid [][]byte, w, h int, directed bool) []Pos {
+	var next [4]Pos
+	n := getOpenNeighbors(from, from, grid, w, h, directed, next[:])
+	for _, i := range next[:n] {
+		prev := from
+		cur := i
+		cells := []Pos{cur}
+		for cost < 0 || len(cells) <= cost {
+			if cur == to {
+				if cost < 0 || len(cells) == cost {
+					return cells
+				}
+				break
+			}
+			var step [4]Pos
+			if getOpenNeighbors(cur, prev, grid, w, h, directed, step[:]) != 1 {
+				break
+			}
+			prev = cur
+			cur = step[0]
+			cells = append(cells, cur)
+		}
+	}
+	log.Fatalln("No trail between junctions")
+	return nil
+}
+
+func getOpenNeighbors(pos, prev Pos, grid [][]byte, w, h int, directed bool, res []Pos) int {
+	count := 0
+	cur := grid[pos.y][pos.x]
+	for _, i := range []struct {
+		dx, dy int
+		slope  byte
+	}{
+		{dx: 0, dy: -1, slope: '^'},
+		{dx: -1, dy: 0, slope: '<'},
+		{dx: 0, dy: 1, slope: 'v'},
+		{dx: 1, dy: 0, slope: '>'},
+	} {
+		v := Pos{
+			x: pos.x + i.dx,
+			y: pos.y + i.dy,
+		}
+		if v == prev || !isInBounds(v, w, h) || grid[v.y][v.x] == '#' {
+			continue
+		}
+		if directed && cur != '.' && cur != i.slope {
+			continue
+		}
+		res[count] = v
+		count++
+	}
+	return count
+}
+
+func renderRoute(w io.Writer, grid [][]byte, route []Pos) {
+	out := make([][]byte, len(grid))
+	for n, i := range grid {
+		out[n] = append([]byte(nil), i...)
+	}
+	for n, i := range route {
+		if n == 0 {
+			out[i.y][i.x] = 'S'
+		} else {
+			out[i.y][i.x] = 'O'
+		}
+	}
+	bw := bufio.NewWriter(w)
+	for _, i := range out {
+		bw.Write(i)
+		bw.WriteByte('\n')
+	}
+	if err := bw.Flush(); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func writeRoutePNG(name string, grid [][]byte, route []Pos) {
+	height := len(grid)
+	width := len(grid[0])
+	img := image.NewRGBA(image.Rect(0, 0, width*routeCellSize, height*routeCellSize))
+	fill := func(x, y int, c color.Color) {
+		for i := 0; i < routeCellSize; i++ {
+			for j := 0; j < routeCellSize; j++ {
+				img.Set(x*routeCellSize+j, y*routeCellSize+i, c)
+			}
+		}
+	}
+	for y, row := range grid {
+		for x, b := range row {
+			switch b {
+			case '#':
+				fill(x, y, color.RGBA{R: 34, G: 68, B: 34, A: 255})
+			case '.':
+				fill(x, y, color.RGBA{R: 224, G: 224, B: 208, A: 255})
+			default:
+				fill(x, y, color.RGBA{R: 96, G: 128, B: 224, A: 255})
+			}
+		}
+	}
+	for _, i := range route {
+		if b := grid[i.y][i.x]; b != '.' {
+			fill(i.x, i.y, color.RGBA{R: 240, G: 160, B: 32, A: 255})
+		} else {
+			fill(i.x, i.y, color.RGBA{R: 208, G: 32, B: 32, A: 255})
+		}
+	}
+
+	file, err := os.Create(name)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			log.Fatalln(err)
+		}
+	}()
+	if err := png.Encode(file, img); err != nil {
+		log.Fatalln(err)
+	}
+}
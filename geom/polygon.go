@@ -0,0 +1,203 @@
+// Package geom provides geometry over rectilinear polygons with integer
+// vertices.
+package geom
+
+import (
+	"errors"
+	"fmt"
+)
+
+type (
+	Point struct {
+		X, Y int
+	}
+
+	// Rect is the rectangle of points from Min to Max inclusive.
+	Rect struct {
+		Min, Max Point
+	}
+
+	// Segment is the line segment from A to B.
+	Segment struct {
+		A, B Point
+	}
+
+	// Polygon is the path through its points in order, where edge i runs from
+	// point i to point i+1. It is closed if the last point is the first.
+	Polygon struct {
+		Points []Point
+	}
+
+	// Intersection is a pair of edges I < J that share more than the end
+	// point joining them, with the part they share.
+	Intersection struct {
+		I, J   int
+		Shared Segment
+	}
+)
+
+var (
+	ErrNotClosed      = errors.New("Polygon not closed")
+	ErrZeroLength     = errors.New("Zero length edge")
+	ErrNotRectilinear = errors.New("Edge not axis aligned")
+	ErrSelfIntersect  = errors.New("Polygon intersects itself")
+)
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// Add returns the point offset by q.
+func (p Point) Add(q Point) Point {
+	return Point{X: p.X + q.X, Y: p.Y + q.Y}
+}
+
+// Bounds returns the smallest rectangle containing the segment.
+func (s Segment) Bounds() Rect {
+	return Rect{
+		Min: Point{X: min(s.A.X, s.B.X), Y: min(s.A.Y, s.B.Y)},
+		Max: Point{X: max(s.A.X, s.B.X), Y: max(s.A.Y, s.B.Y)},
+	}
+}
+
+// Len returns the length of an axis aligned segment.
+func (s Segment) Len() int {
+	return abs(s.B.X-s.A.X) + abs(s.B.Y-s.A.Y)
+}
+
+// IsRectilinear reports whether the segment is horizontal or vertical.
+func (s Segment) IsRectilinear() bool {
+	return s.A.X == s.B.X || s.A.Y == s.B.Y
+}
+
+// Intersect returns the part shared by two axis aligned segments, which is
+// a segment or a single point, or false if they are disjoint.
+func (s Segment) Intersect(t Segment) (Segment, bool) {
+	a := s.Bounds()
+	b := t.Bounds()
+	r := Rect{
+		Min: Point{X: max(a.Min.X, b.Min.X), Y: max(a.Min.Y, b.Min.Y)},
+		Max: Point{X: min(a.Max.X, b.Max.X), Y: min(a.Max.Y, b.Max.Y)},
+	}
+	if r.Min.X > r.Max.X || r.Min.Y > r.Max.Y {
+		return Segment{}, false
+	}
+	return Segment{A: r.Min, B: r.Max}, true
+}
+
+// Move appends the point offset by d from the last point, or from the origin
+// if there are none.
+func (p *Polygon) Move(d Point) {
+	last := Point{}
+	if len(p.Points) > 0 {
+		last = p.Points[len(p.Points)-1]
+	}
+	p.Points = append(p.Points, last.Add(d))
+}
+
+// NumEdges returns the number of edges.
+func (p Polygon) NumEdges() int {
+	return max(len(p.Points)-1, 0)
+}
+
+// Edge returns edge i.
+func (p Polygon) Edge(i int) Segment {
+	return Segment{A: p.Points[i], B: p.Points[i+1]}
+}
+
+// IsClosed reports whether the path returns to its first point.
+func (p Polygon) IsClosed() bool {
+	return len(p.Points) > 1 && p.Points[0] == p.Points[len(p.Points)-1]
+}
+
+// SignedArea returns the area enclosed by a closed polygon by the shoelace
+// formula. It is positive when the points run clockwise with y pointing
+// down, which is counterclockwise with y pointing up. For a rectilinear
+// polygon with integer points it is an integer.
+func (p Polygon) SignedArea() int {
+	sum := 0
+	for i := 0; i < p.NumEdges(); i++ {
+		a, b := p.Points[i], p.Points[i+1]
+		sum += a.X*b.Y - b.X*a.Y
+	}
+	return sum / 2
+}
+
+// Perimeter returns the total length of the axis aligned edges, which is the
+// number of lattice points on the boundary of a closed polygon.
+func (p Polygon) Perimeter() int {
+	sum := 0
+	for i := 0; i < p.NumEdges(); i++ {
+		sum += p.Edge(i).Len()
+	}
+	return sum
+}
+
+// InteriorPoints returns the number of lattice points strictly inside a
+// closed simple polygon by Pick's theorem, A = I + B/2 - 1.
+func (p Polygon) InteriorPoints() int {
+	return abs(p.SignedArea()) - p.Perimeter()/2 + 1
+}
+
+// LatticePoints returns the number of lattice points inside or on the
+// boundary of a closed simple polygon.
+func (p Polygon) LatticePoints() int {
+	return abs(p.SignedArea()) + p.Perimeter()/2 + 1
+}
+
+// Bounds returns the smallest rectangle containing every point.
+func (p Polygon) Bounds() Rect {
+	if len(p.Points) == 0 {
+		return Rect{}
+	}
+	r := Rect{Min: p.Points[0], Max: p.Points[0]}
+	for _, i := range p.Points[1:] {
+		r.Min.X = min(r.Min.X, i.X)
+		r.Min.Y = min(r.Min.Y, i.Y)
+		r.Max.X = max(r.Max.X, i.X)
+		r.Max.Y = max(r.Max.Y, i.Y)
+	}
+	return r
+}
+
+// adjacent reports whether edges i < j meet end to end, including the last
+// edge and the first of a closed polygon, and returns the point they meet
+// at.
+func (p Polygon) adjacent(i, j int) (Point, bool) {
+	if j == i+1 {
+		return p.Points[j], true
+	}
+	if i == 0 && j == p.NumEdges()-1 && p.IsClosed() {
+		return p.Points[0], true
+	}
+	return Point{}, false
+}
+
+// Validate checks that the polygon is closed, that every edge is axis
+// aligned with a non-zero length, and that no edges intersect other than
+// adjacent edges at the point joining them.
+func (p Polygon) Validate() error {
+	if len(p.Points) == 0 {
+		return fmt.Errorf("%w: no points", ErrNotClosed)
+	}
+	if !p.IsClosed() {
+		last := p.Points[len(p.Points)-1]
+		return fmt.Errorf("%w: path ends at %d,%d", ErrNotClosed, last.X, last.Y)
+	}
+	for i := 0; i < p.NumEdges(); i++ {
+		e := p.Edge(i)
+		if !e.IsRectilinear() {
+			return fmt.Errorf("%w: edge %d", ErrNotRectilinear, i)
+		}
+		if e.Len() == 0 {
+			return fmt.Errorf("%w: edge %d", ErrZeroLength, i)
+		}
+	}
+	if k := p.Intersections(); len(k) > 0 {
+		return fmt.Errorf("%w: edges %d and %d", ErrSelfIntersect, k[0].I, k[0].J)
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package geom
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"testing"
+	"testing/quick"
+)
+
+var dirDeltas = map[byte]Point{
+	'U': {X: 0, Y: -1},
+	'D': {X: 0, Y: 1},
+	'L': {X: -1, Y: 0},
+	'R': {X: 1, Y: 0},
+}
+
+func polygonFromMoves(t *testing.T, moves []string) Polygon {
+	t.Helper()
+	p := Polygon{Points: []Point{{}}}
+	for _, i := range moves {
+		d, ok := dirDeltas[i[0]]
+		if !ok {
+			t.Fatalf("Invalid move %q", i)
+		}
+		n, err := strconv.Atoi(i[1:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.Move(Point{X: d.X * n, Y: d.Y * n})
+	}
+	return p
+}
+
+func colorPolygon(t *testing.T, colors []string) Polygon {
+	t.Helper()
+	p := Polygon{Points: []Point{{}}}
+	for _, i := range colors {
+		n, err := strconv.ParseInt(i[:5], 16, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d := dirDeltas["RDLU"[i[5]-'0']]
+		p.Move(Point{X: d.X * int(n), Y: d.Y * int(n)})
+	}
+	return p
+}
+
+func TestPolygonDigPlan(t *testing.T) {
+	for tcn, tc := range []struct {
+		p      Polygon
+		exp    int
+		bounds Rect
+	}{
+		{
+			p: polygonFromMoves(t, []string{
+				"R6", "D5", "L2", "D2", "R2", "D2", "L5", "U2", "L1", "U2", "R2", "U3", "L2", "U2",
+			}),
+			exp:    62,
+			bounds: Rect{Max: Point{X: 6, Y: 9}},
+		},
+		{
+			p: colorPolygon(t, []string{
+				"70c710", "0dc571", "5713f0", "d2c081", "59c680", "411b91", "8ceee2",
+				"caa173", "1b58a2", "caa171", "7807d2", "a77fa3", "015232", "7a21e3",
+			}),
+			exp:    952408144115,
+			bounds: Rect{Max: Point{X: 1186328, Y: 1186328}},
+		},
+	} {
+		tc := tc
+		t.Run("dig plan test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			if err := tc.p.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			if v := tc.p.LatticePoints(); v != tc.exp {
+				t.Fatalf("Invalid output %d != %d", v, tc.exp)
+			}
+			if v := tc.p.Bounds(); v != tc.bounds {
+				t.Fatalf("Invalid bounds %v != %v", v, tc.bounds)
+			}
+		})
+	}
+}
+
+func TestPolygonRect(t *testing.T) {
+	for tcn, tc := range []struct {
+		p     Polygon
+		area  int
+		perim int
+		inner int
+		exp   int
+	}{
+		{
+			p:     polygonFromMoves(t, []string{"R4", "D3", "L4", "U3"}),
+			area:  12,
+			perim: 14,
+			inner: 6,
+			exp:   20,
+		},
+		{
+			p:     polygonFromMoves(t, []string{"D3", "R4", "U3", "L4"}),
+			area:  -12,
+			perim: 14,
+			inner: 6,
+			exp:   20,
+		},
+	} {
+		tc := tc
+		t.Run("rect test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			if v := tc.p.SignedArea(); v != tc.area {
+				t.Fatalf("Invalid area %d != %d", v, tc.area)
+			}
+			if v := tc.p.Perimeter(); v != tc.perim {
+				t.Fatalf("Invalid perimeter %d != %d", v, tc.perim)
+			}
+			if v := tc.p.InteriorPoints(); v != tc.inner {
+				t.Fatalf("Invalid interior points %d != %d", v, tc.inner)
+			}
+			if v := tc.p.LatticePoints(); v != tc.exp {
+				t.Fatalf("Invalid output %d != %d", v, tc.exp)
+			}
+		})
+	}
+}
+
+func TestPolygonValidate(t *testing.T) {
+	for tcn, tc := range []struct {
+		p   Polygon
+		err error
+	}{
+		// simple
+		{p: polygonFromMoves(t, []string{"R2", "D2", "R2", "D2", "L4", "U4"})},
+		// collinear
+		{p: polygonFromMoves(t, []string{"R2", "R2", "D2", "L4", "U2"})},
+		// open
+		{p: polygonFromMoves(t, []string{"R2", "D2", "L2"}), err: ErrNotClosed},
+		// zero
+		{p: polygonFromMoves(t, []string{"R2", "D0", "D2", "L2", "U2"}), err: ErrZeroLength},
+		// crossing
+		{p: polygonFromMoves(t, []string{"R4", "D2", "L2", "U4", "L2", "D2"}), err: ErrSelfIntersect},
+		// touching
+		{p: polygonFromMoves(t, []string{"R2", "D2", "R2", "D2", "L2", "U2", "L2", "U2"}), err: ErrSelfIntersect},
+		// backtrack
+		{p: polygonFromMoves(t, []string{"R4", "L2", "D2", "L2", "U2"}), err: ErrSelfIntersect},
+		// overlap
+		{p: polygonFromMoves(t, []string{"R4", "D2", "L2", "U2", "R1", "D4", "L3", "U4"}), err: ErrSelfIntersect},
+		// diagonal
+		{p: Polygon{Points: []Point{{}, {X: 1, Y: 1}, {}}}, err: ErrNotRectilinear},
+	} {
+		tc := tc
+		t.Run("validate test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			err := tc.p.Validate()
+			if tc.err == nil && err != nil {
+				t.Fatal(err)
+			}
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("Invalid error %v != %v", err, tc.err)
+			}
+		})
+	}
+}
+
+type (
+	// histogram is a simple polygon made of columns of width 1 standing on
+	// the x axis.
+	histogram struct {
+		heights []int
+	}
+)
+
+func (histogram) Generate(r *rand.Rand, size int) reflect.Value {
+	h := histogram{heights: make([]int, r.Intn(8)+1)}
+	for n := range h.heights {
+		h.heights[n] = r.Intn(6) + 1
+	}
+	return reflect.ValueOf(h)
+}
+
+func (h histogram) polygon() Polygon {
+	p := Polygon{Points: []Point{{}}}
+	y := 0
+	for _, i := range h.heights {
+		if i != y {
+			p.Move(Point{X: 0, Y: i - y})
+			y = i
+		}
+		p.Move(Point{X: 1, Y: 0})
+	}
+	p.Move(Point{X: 0, Y: -y})
+	p.Move(Point{X: -len(h.heights), Y: 0})
+	return p
+}
+
+// classifyPointRef reports whether a lattice point is on the boundary of a
+// polygon and otherwise whether it is inside by casting a ray to the right.
+func classifyPointRef(p Polygon, q Point) (boundary, inside bool) {
+	for i := 0; i < p.NumEdges(); i++ {
+		if _, ok := p.Edge(i).Intersect(Segment{A: q, B: q}); ok {
+			return true, false
+		}
+	}
+	for i := 0; i < p.NumEdges(); i++ {
+		e := p.Edge(i)
+		if e.A.X != e.B.X || e.A.X < q.X {
+			continue
+		}
+		if min(e.A.Y, e.B.Y) <= q.Y && q.Y < max(e.A.Y, e.B.Y) {
+			inside = !inside
+		}
+	}
+	return false, inside
+}
+
+func TestPolygonLatticeQuick(t *testing.T) {
+	if err := quick.Check(func(h histogram) bool {
+		p := h.polygon()
+		if p.Validate() != nil {
+			return false
+		}
+		b := p.Bounds()
+		interior, lattice := 0, 0
+		for y := b.Min.Y; y <= b.Max.Y; y++ {
+			for x := b.Min.X; x <= b.Max.X; x++ {
+				boundary, inside := classifyPointRef(p, Point{X: x, Y: y})
+				if inside {
+					interior++
+				}
+				if boundary || inside {
+					lattice++
+				}
+			}
+		}
+		return p.InteriorPoints() == interior && p.LatticePoints() == lattice
+	}, nil); err != nil {
+		t.Fatal(err)
+	}
+}
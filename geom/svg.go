@@ -0,0 +1,40 @@
+package geom
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// WriteSVG draws the polygon as a filled path scaled to the given width in
+// pixels, with the first point marked. The stroke does not scale with the
+// polygon, so that very large polygons stay legible.
+func (p Polygon) WriteSVG(w io.Writer, width int) error {
+	bw := bufio.NewWriter(w)
+	b := p.Bounds()
+	dx := max(b.Max.X-b.Min.X, 1)
+	dy := max(b.Max.Y-b.Min.Y, 1)
+	// pad by a fiftieth of the larger side so the outline is not clipped
+	pad := max(dx, dy)/50 + 1
+	height := max(width*(dy+2*pad)/(dx+2*pad), 1)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="%d %d %d %d">`+"\n",
+		width, height, b.Min.X-pad, b.Min.Y-pad, dx+2*pad, dy+2*pad)
+	bw.WriteString(`<path fill="#d9a066" fill-opacity="0.6" fill-rule="evenodd" stroke="#3f2a14" stroke-width="1.5" vector-effect="non-scaling-stroke" d="`)
+	for n, i := range p.Points {
+		cmd := 'L'
+		if n == 0 {
+			cmd = 'M'
+		}
+		fmt.Fprintf(bw, "%c%d %d ", cmd, i.X, i.Y)
+	}
+	if p.IsClosed() {
+		bw.WriteString("Z")
+	}
+	bw.WriteString("\"/>\n")
+	if len(p.Points) > 0 {
+		start := p.Points[0]
+		fmt.Fprintf(bw, `<circle cx="%d" cy="%d" r="%d" fill="#c03030"/>`+"\n", start.X, start.Y, max(pad/2, 1))
+	}
+	bw.WriteString("</svg>\n")
+	return bw.Flush()
+}
@@ -0,0 +1,163 @@
+package geom
+
+import (
+	"cmp"
+	"slices"
+)
+
+type (
+	// sweepEdge is an axis aligned edge by its index, the coordinate it lies
+	// on, and the range [lo, hi] it covers along the other axis.
+	sweepEdge struct {
+		i      int
+		at     int
+		lo, hi int
+	}
+
+	// sweepEvent is the start or end of a horizontal edge, or a vertical
+	// edge, at x.
+	sweepEvent struct {
+		x    int
+		kind int
+		e    sweepEdge
+	}
+)
+
+// events at the same x are ordered so that the closed ranges of horizontal
+// edges include their end points
+const (
+	eventStart = iota
+	eventVertical
+	eventEnd
+)
+
+// Intersections returns every pair of edges that share a point, other than
+// adjacent edges that share only the point joining them, ordered by I and
+// then J. Edges that are not axis aligned are skipped, and edges of zero
+// length are treated as horizontal.
+//
+// Crossings of horizontal and vertical edges are found by sweeping a
+// vertical line from left to right over the horizontal edges that it
+// crosses, kept sorted by y, and overlaps of parallel edges by sweeping
+// along each line that edges lie on. The active edges are a sorted slice, so
+// each insert and delete moves O(n) edges, and for n edges with k shared
+// points the sweep is O(n^2 + k) in the worst case rather than
+// O((n + k) log n). The copies are cheap for polygons of a few thousand
+// edges.
+func (p Polygon) Intersections() []Intersection {
+	var horizontal, vertical []sweepEdge
+	for i := 0; i < p.NumEdges(); i++ {
+		e := p.Edge(i)
+		switch {
+		case e.A.Y == e.B.Y:
+			horizontal = append(horizontal, sweepEdge{
+				i:  i,
+				at: e.A.Y,
+				lo: min(e.A.X, e.B.X),
+				hi: max(e.A.X, e.B.X),
+			})
+		case e.A.X == e.B.X:
+			vertical = append(vertical, sweepEdge{
+				i:  i,
+				at: e.A.X,
+				lo: min(e.A.Y, e.B.Y),
+				hi: max(e.A.Y, e.B.Y),
+			})
+		}
+	}
+
+	var pairs [][2]int
+	report := func(i, j int) {
+		pairs = append(pairs, [2]int{min(i, j), max(i, j)})
+	}
+
+	events := make([]sweepEvent, 0, 2*len(horizontal)+len(vertical))
+	for _, i := range horizontal {
+		events = append(events,
+			sweepEvent{x: i.lo, kind: eventStart, e: i},
+			sweepEvent{x: i.hi, kind: eventEnd, e: i},
+		)
+	}
+	for _, i := range vertical {
+		events = append(events, sweepEvent{x: i.at, kind: eventVertical, e: i})
+	}
+	slices.SortFunc(events, func(a, b sweepEvent) int {
+		if c := cmp.Compare(a.x, b.x); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.kind, b.kind)
+	})
+	cmpActive := func(a sweepEdge, b sweepEdge) int {
+		if c := cmp.Compare(a.at, b.at); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.i, b.i)
+	}
+	// horizontal edges crossed by the sweep line sorted by y
+	var active []sweepEdge
+	for _, ev := range events {
+		switch ev.kind {
+		case eventStart:
+			k, _ := slices.BinarySearchFunc(active, ev.e, cmpActive)
+			active = slices.Insert(active, k, ev.e)
+		case eventEnd:
+			if k, ok := slices.BinarySearchFunc(active, ev.e, cmpActive); ok {
+				active = slices.Delete(active, k, k+1)
+			}
+		case eventVertical:
+			k, _ := slices.BinarySearchFunc(active, ev.e.lo, func(a sweepEdge, y int) int {
+				return cmp.Compare(a.at, y)
+			})
+			for ; k < len(active) && active[k].at <= ev.e.hi; k++ {
+				report(active[k].i, ev.e.i)
+			}
+		}
+	}
+
+	sweepParallel(horizontal, report)
+	sweepParallel(vertical, report)
+
+	slices.SortFunc(pairs, func(a, b [2]int) int {
+		if c := cmp.Compare(a[0], b[0]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a[1], b[1])
+	})
+	var res []Intersection
+	for _, i := range pairs {
+		shared, _ := p.Edge(i[0]).Intersect(p.Edge(i[1]))
+		if joint, ok := p.adjacent(i[0], i[1]); ok && shared.A == joint && shared.B == joint {
+			continue
+		}
+		res = append(res, Intersection{I: i[0], J: i[1], Shared: shared})
+	}
+	return res
+}
+
+// sweepParallel reports every pair of edges that lie on the same line and
+// overlap, by sweeping each line in order of the start of its edges.
+func sweepParallel(edges []sweepEdge, report func(i, j int)) {
+	edges = slices.Clone(edges)
+	slices.SortFunc(edges, func(a, b sweepEdge) int {
+		if c := cmp.Compare(a.at, b.at); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.lo, b.lo)
+	})
+	// edges on the current line that reach past the start of the last edge
+	var active []sweepEdge
+	for n, e := range edges {
+		if n == 0 || edges[n-1].at != e.at {
+			active = active[:0]
+		}
+		k := 0
+		for _, i := range active {
+			if i.hi >= e.lo {
+				report(i.i, e.i)
+				active[k] = i
+				k++
+			}
+		}
+		active = append(active[:k], e)
+	}
+}
@@ -0,0 +1,67 @@
+package geom
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+)
+
+// intersectionsRef compares every pair of edges.
+func intersectionsRef(p Polygon) []Intersection {
+	var res []Intersection
+	n := p.NumEdges()
+	for i := 0; i < n; i++ {
+		a := p.Edge(i)
+		for j := i + 1; j < n; j++ {
+			shared, ok := a.Intersect(p.Edge(j))
+			if !ok {
+				continue
+			}
+			if joint, ok := p.adjacent(i, j); ok && shared.A == joint && shared.B == joint {
+				continue
+			}
+			res = append(res, Intersection{I: i, J: j, Shared: shared})
+		}
+	}
+	return res
+}
+
+type (
+	// randomWalk is a rectilinear path with short moves, some of zero length,
+	// that is closed about half of the time.
+	randomWalk struct {
+		p Polygon
+	}
+)
+
+func (randomWalk) Generate(r *rand.Rand, size int) reflect.Value {
+	w := randomWalk{p: Polygon{Points: []Point{{}}}}
+	for i := r.Intn(12) + 1; i > 0; i-- {
+		n := r.Intn(5)
+		if r.Intn(2) == 0 {
+			n = -n
+		}
+		if r.Intn(2) == 0 {
+			w.p.Move(Point{X: n, Y: 0})
+		} else {
+			w.p.Move(Point{X: 0, Y: n})
+		}
+	}
+	if r.Intn(2) == 0 {
+		last := w.p.Points[len(w.p.Points)-1]
+		w.p.Move(Point{X: -last.X, Y: 0})
+		w.p.Move(Point{X: 0, Y: -last.Y})
+	}
+	return reflect.ValueOf(w)
+}
+
+func TestIntersectionsQuick(t *testing.T) {
+	if err := quick.CheckEqual(func(w randomWalk) []Intersection {
+		return w.p.Intersections()
+	}, func(w randomWalk) []Intersection {
+		return intersectionsRef(w.p)
+	}, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Error(err)
+	}
+}
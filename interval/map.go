@@ -0,0 +1,268 @@
+// Package interval provides algebra over half-open integer intervals.
+package interval
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"sort"
+)
+
+type (
+	// Range is the half-open range of integers [Start, End).
+	Range struct {
+		Start int
+		End   int
+	}
+
+	// Rule shifts every integer in Src by Offset.
+	Rule struct {
+		Src    Range
+		Offset int
+	}
+
+	// Segment is a maximal run of integers shifted by the same non-zero
+	// Offset.
+	Segment struct {
+		Range
+		Offset int
+	}
+
+	// Map is a piecewise translation of the integers. It is stored as sorted,
+	// disjoint segments, and integers outside of every segment map to
+	// themselves. Adjacent segments always have different offsets, so equal
+	// maps have equal segments.
+	Map struct {
+		segs []Segment
+	}
+)
+
+// Len returns the number of integers in the range.
+func (r Range) Len() int {
+	return r.End - r.Start
+}
+
+// IsEmpty reports whether the range contains no integers.
+func (r Range) IsEmpty() bool {
+	return r.End <= r.Start
+}
+
+// Contains reports whether x is in the range.
+func (r Range) Contains(x int) bool {
+	return x >= r.Start && x < r.End
+}
+
+// Intersect returns the range of integers in both r and o.
+func (r Range) Intersect(o Range) Range {
+	return Range{
+		Start: max(r.Start, o.Start),
+		End:   min(r.End, o.End),
+	}
+}
+
+// NewMap creates a map from rules. Where rules overlap, the earlier rule
+// takes precedence, as if each integer were sent through the first rule
+// whose source contains it.
+func NewMap(rules []Rule) Map {
+	var segs []Segment
+	var covered Set
+	for _, i := range rules {
+		for _, f := range NewSet(i.Src).Subtract(covered).ranges {
+			segs = append(segs, Segment{
+				Range:  f,
+				Offset: i.Offset,
+			})
+		}
+		covered.Insert(i.Src)
+	}
+	slices.SortFunc(segs, func(a, b Segment) int {
+		return cmp.Compare(a.Start, b.Start)
+	})
+	var b mapBuilder
+	for _, i := range segs {
+		b.add(i.Start, i.End, i.Offset)
+	}
+	return b.m
+}
+
+type (
+	mapBuilder struct {
+		m Map
+	}
+)
+
+// add appends a piece that must start at or after the end of every piece
+// added before it, merging it with the previous segment when possible and
+// dropping it if it is the identity.
+func (b *mapBuilder) add(start, end, offset int) {
+	if end <= start || offset == 0 {
+		return
+	}
+	if n := len(b.m.segs); n > 0 {
+		last := &b.m.segs[n-1]
+		if last.End == start && last.Offset == offset {
+			last.End = end
+			return
+		}
+	}
+	b.m.segs = append(b.m.segs, Segment{
+		Range: Range{
+			Start: start,
+			End:   end,
+		},
+		Offset: offset,
+	})
+}
+
+// Segments returns the segments of the map in order.
+func (m Map) Segments() []Segment {
+	return slices.Clone(m.segs)
+}
+
+// Equal reports whether both maps send every integer to the same place.
+func (m Map) Equal(o Map) bool {
+	return slices.Equal(m.segs, o.segs)
+}
+
+// Apply returns the image of x.
+func (m Map) Apply(x int) int {
+	idx := sort.Search(len(m.segs), func(i int) bool {
+		return m.segs[i].End > x
+	})
+	if idx < len(m.segs) && m.segs[idx].Contains(x) {
+		return x + m.segs[idx].Offset
+	}
+	return x
+}
+
+// pieces calls fn for every maximal sub range of r on which the map is a
+// single translation, in order, including the identity gaps between
+// segments.
+func (m Map) pieces(r Range, fn func(start, end, offset int)) {
+	cur := r.Start
+	idx := sort.Search(len(m.segs), func(i int) bool {
+		return m.segs[i].End > cur
+	})
+	for ; idx < len(m.segs) && cur < r.End; idx++ {
+		s := m.segs[idx]
+		if s.Start >= r.End {
+			break
+		}
+		if s.Start > cur {
+			fn(cur, s.Start, 0)
+			cur = s.Start
+		}
+		end := min(s.End, r.End)
+		fn(cur, end, s.Offset)
+		cur = end
+	}
+	if cur < r.End {
+		fn(cur, r.End, 0)
+	}
+}
+
+var fullRange = Range{
+	Start: math.MinInt,
+	End:   math.MaxInt,
+}
+
+// ApplySet returns the image of a set.
+func (m Map) ApplySet(s Set) Set {
+	var res []Range
+	for _, i := range s.ranges {
+		m.pieces(i, func(start, end, offset int) {
+			res = append(res, Range{
+				Start: start + offset,
+				End:   end + offset,
+			})
+		})
+	}
+	return NewSet(res...)
+}
+
+// Preimage returns the set of integers that the map sends into s.
+func (m Map) Preimage(s Set) Set {
+	var res []Range
+	m.pieces(fullRange, func(start, end, offset int) {
+		for _, i := range s.ranges {
+			img := Range{
+				Start: start + offset,
+				End:   end + offset,
+			}
+			if k := img.Intersect(i); !k.IsEmpty() {
+				res = append(res, Range{
+					Start: k.Start - offset,
+					End:   k.End - offset,
+				})
+			}
+		}
+	})
+	return NewSet(res...)
+}
+
+// Then returns the map that applies m and then o. Each piece of m is split
+// where its image crosses a boundary of o, and since translations preserve
+// order, the resulting pieces are already in order.
+func (m Map) Then(o Map) Map {
+	var pieces []Segment
+	m.pieces(fullRange, func(start, end, offset int) {
+		o.pieces(Range{
+			Start: start + offset,
+			End:   end + offset,
+		}, func(s, e, p int) {
+			pieces = append(pieces, Segment{
+				Range: Range{
+					Start: s - offset,
+					End:   e - offset,
+				},
+				Offset: offset + p,
+			})
+		})
+	})
+	var b mapBuilder
+	for _, i := range pieces {
+		b.add(i.Start, i.End, i.Offset)
+	}
+	return b.m
+}
+
+// Compose returns the map that applies each map in order.
+func Compose(maps ...Map) Map {
+	var res Map
+	for _, i := range maps {
+		res = res.Then(i)
+	}
+	return res
+}
+
+// Inverse returns the inverse of the map, if the map is a bijection.
+func (m Map) Inverse() (Map, bool) {
+	var images []Segment
+	m.pieces(fullRange, func(start, end, offset int) {
+		images = append(images, Segment{
+			Range: Range{
+				Start: start + offset,
+				End:   end + offset,
+			},
+			Offset: -offset,
+		})
+	})
+	slices.SortFunc(images, func(a, b Segment) int {
+		return cmp.Compare(a.Start, b.Start)
+	})
+	cur := fullRange.Start
+	for _, i := range images {
+		if i.Start != cur {
+			return Map{}, false
+		}
+		cur = i.End
+	}
+	if cur != fullRange.End {
+		return Map{}, false
+	}
+	var b mapBuilder
+	for _, i := range images {
+		b.add(i.Start, i.End, i.Offset)
+	}
+	return b.m, true
+}
@@ -0,0 +1,191 @@
+package interval
+
+import (
+	"math/rand"
+	"reflect"
+	"slices"
+	"strconv"
+	"testing"
+	"testing/quick"
+)
+
+const (
+	testDomain = 40
+	testLo     = -20
+	testHi     = 60
+)
+
+type (
+	rulesInput struct {
+		rules [][]Rule
+		input []Range
+	}
+)
+
+func (rulesInput) Generate(r *rand.Rand, size int) reflect.Value {
+	var in rulesInput
+	for i := r.Intn(3) + 1; i > 0; i-- {
+		var rules []Rule
+		for j := r.Intn(6); j > 0; j-- {
+			start := r.Intn(testDomain)
+			rules = append(rules, Rule{
+				Src: Range{
+					Start: start,
+					End:   start + r.Intn(12),
+				},
+				Offset: r.Intn(21) - 10,
+			})
+		}
+		in.rules = append(in.rules, rules)
+	}
+	for i := r.Intn(4); i > 0; i-- {
+		start := r.Intn(testDomain) - 5
+		in.input = append(in.input, Range{
+			Start: start,
+			End:   start + r.Intn(16),
+		})
+	}
+	return reflect.ValueOf(in)
+}
+
+func applyRulesRef(rules []Rule, x int) int {
+	for _, i := range rules {
+		if i.Src.Contains(x) {
+			return x + i.Offset
+		}
+	}
+	return x
+}
+
+func pointsOf(ranges []Range) []int {
+	var points []int
+	for _, i := range ranges {
+		for j := i.Start; j < i.End; j++ {
+			points = append(points, j)
+		}
+	}
+	slices.Sort(points)
+	return slices.Compact(points)
+}
+
+func TestMapApply(t *testing.T) {
+	if err := quick.Check(func(in rulesInput) bool {
+		m := NewMap(in.rules[0])
+		for x := testLo; x < testHi; x++ {
+			if m.Apply(x) != applyRulesRef(in.rules[0], x) {
+				return false
+			}
+		}
+		return true
+	}, &quick.Config{
+		MaxCount: 2048,
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMapCompose(t *testing.T) {
+	if err := quick.Check(func(in rulesInput) bool {
+		maps := make([]Map, len(in.rules))
+		for n, i := range in.rules {
+			maps[n] = NewMap(i)
+		}
+		m := Compose(maps...)
+		for x := testLo; x < testHi; x++ {
+			k := x
+			for _, i := range in.rules {
+				k = applyRulesRef(i, k)
+			}
+			if m.Apply(x) != k {
+				return false
+			}
+		}
+		return true
+	}, &quick.Config{
+		MaxCount: 2048,
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMapApplySet(t *testing.T) {
+	if err := quick.Check(func(in rulesInput) bool {
+		m := NewMap(in.rules[0])
+		var exp []int
+		for _, i := range pointsOf(in.input) {
+			exp = append(exp, applyRulesRef(in.rules[0], i))
+		}
+		slices.Sort(exp)
+		exp = slices.Compact(exp)
+		return slices.Equal(pointsOf(m.ApplySet(NewSet(in.input...)).Ranges()), exp)
+	}, &quick.Config{
+		MaxCount: 2048,
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMapPreimage(t *testing.T) {
+	if err := quick.Check(func(in rulesInput) bool {
+		m := NewMap(in.rules[0])
+		target := pointsOf(in.input)
+		var exp []int
+		// offsets are bounded, so only points near the target can map into it
+		for x := testLo - 20; x < testHi+20; x++ {
+			if _, ok := slices.BinarySearch(target, applyRulesRef(in.rules[0], x)); ok {
+				exp = append(exp, x)
+			}
+		}
+		return slices.Equal(pointsOf(m.Preimage(NewSet(in.input...)).Ranges()), exp)
+	}, &quick.Config{
+		MaxCount: 2048,
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMapInverse(t *testing.T) {
+	for tcn, tc := range []struct {
+		rules []Rule
+		ok    bool
+	}{
+		{
+			// swaps [0, 4) and [4, 10)
+			rules: []Rule{
+				{Src: Range{Start: 0, End: 4}, Offset: 6},
+				{Src: Range{Start: 4, End: 10}, Offset: -4},
+			},
+			ok: true,
+		},
+		{
+			rules: []Rule{
+				{Src: Range{Start: 0, End: 4}, Offset: 4},
+			},
+			ok: false,
+		},
+		{
+			rules: nil,
+			ok:    true,
+		},
+	} {
+		tc := tc
+		t.Run("inverse test case "+strconv.Itoa(tcn), func(t *testing.T) {
+			m := NewMap(tc.rules)
+			inv, ok := m.Inverse()
+			if ok != tc.ok {
+				t.Fatalf("Invalid bijection check %t != %t", ok, tc.ok)
+			}
+			if !ok {
+				return
+			}
+			for x := testLo; x < testHi; x++ {
+				if v := inv.Apply(m.Apply(x)); v != x {
+					t.Fatalf("Invalid inverse %d != %d", v, x)
+				}
+			}
+			if !m.Then(inv).Equal(Map{}) {
+				t.Fatalf("Composition with inverse is not the identity")
+			}
+		})
+	}
+}
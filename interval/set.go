@@ -0,0 +1,172 @@
+package interval
+
+import (
+	"cmp"
+	"slices"
+	"sort"
+)
+
+type (
+	// Set is a set of integers stored as sorted, disjoint, non-adjacent,
+	// non-empty ranges, so equal sets have equal ranges. Operations never
+	// modify the ranges of another set, so sets may be copied freely.
+	Set struct {
+		ranges []Range
+	}
+)
+
+// NewSet creates the union of ranges.
+func NewSet(ranges ...Range) Set {
+	ranges = slices.DeleteFunc(slices.Clone(ranges), Range.IsEmpty)
+	slices.SortFunc(ranges, func(a, b Range) int {
+		return cmp.Compare(a.Start, b.Start)
+	})
+	res := ranges[:0]
+	for _, i := range ranges {
+		if n := len(res); n > 0 && i.Start <= res[n-1].End {
+			res[n-1].End = max(res[n-1].End, i.End)
+			continue
+		}
+		res = append(res, i)
+	}
+	return Set{
+		ranges: res,
+	}
+}
+
+// Ranges returns the ranges of the set in order.
+func (s Set) Ranges() []Range {
+	return slices.Clone(s.ranges)
+}
+
+// IsEmpty reports whether the set has no integers.
+func (s Set) IsEmpty() bool {
+	return len(s.ranges) == 0
+}
+
+// Len returns the number of integers in the set.
+func (s Set) Len() int {
+	total := 0
+	for _, i := range s.ranges {
+		total += i.Len()
+	}
+	return total
+}
+
+// Equal reports whether both sets have the same integers.
+func (s Set) Equal(o Set) bool {
+	return slices.Equal(s.ranges, o.ranges)
+}
+
+// Contains reports whether x is in the set.
+func (s Set) Contains(x int) bool {
+	idx := sort.Search(len(s.ranges), func(i int) bool {
+		return s.ranges[i].End > x
+	})
+	return idx < len(s.ranges) && s.ranges[idx].Contains(x)
+}
+
+// span returns the indices of the ranges that overlap or touch r.
+func (s Set) span(r Range) (int, int) {
+	lo := sort.Search(len(s.ranges), func(i int) bool {
+		return s.ranges[i].End >= r.Start
+	})
+	hi := sort.Search(len(s.ranges), func(i int) bool {
+		return s.ranges[i].Start > r.End
+	})
+	return lo, hi
+}
+
+// Insert adds the integers of r to the set.
+func (s *Set) Insert(r Range) {
+	if r.IsEmpty() {
+		return
+	}
+	lo, hi := s.span(r)
+	if lo < hi {
+		r.Start = min(r.Start, s.ranges[lo].Start)
+		r.End = max(r.End, s.ranges[hi-1].End)
+	}
+	next := make([]Range, 0, len(s.ranges)-(hi-lo)+1)
+	next = append(next, s.ranges[:lo]...)
+	next = append(next, r)
+	next = append(next, s.ranges[hi:]...)
+	s.ranges = next
+}
+
+// Remove removes the integers of r from the set.
+func (s *Set) Remove(r Range) {
+	if r.IsEmpty() {
+		return
+	}
+	lo, hi := s.span(r)
+	next := make([]Range, 0, len(s.ranges)+1)
+	next = append(next, s.ranges[:lo]...)
+	for _, i := range s.ranges[lo:hi] {
+		if l := (Range{Start: i.Start, End: min(i.End, r.Start)}); !l.IsEmpty() {
+			next = append(next, l)
+		}
+		if u := (Range{Start: max(i.Start, r.End), End: i.End}); !u.IsEmpty() {
+			next = append(next, u)
+		}
+	}
+	next = append(next, s.ranges[hi:]...)
+	s.ranges = next
+}
+
+// Union returns the integers in either set.
+func (s Set) Union(o Set) Set {
+	return NewSet(append(slices.Clone(s.ranges), o.ranges...)...)
+}
+
+// Intersect returns the integers in both sets.
+func (s Set) Intersect(o Set) Set {
+	var res []Range
+	a, b := s.ranges, o.ranges
+	for len(a) > 0 && len(b) > 0 {
+		if k := a[0].Intersect(b[0]); !k.IsEmpty() {
+			res = append(res, k)
+		}
+		if a[0].End < b[0].End {
+			a = a[1:]
+		} else {
+			b = b[1:]
+		}
+	}
+	return Set{
+		ranges: res,
+	}
+}
+
+// Subtract returns the integers in s that are not in o.
+func (s Set) Subtract(o Set) Set {
+	var res []Range
+	b := o.ranges
+	for _, i := range s.ranges {
+		cur := i
+		for len(b) > 0 && b[0].End <= cur.Start {
+			b = b[1:]
+		}
+		for _, j := range b {
+			if j.Start >= cur.End {
+				break
+			}
+			if j.Start > cur.Start {
+				res = append(res, Range{
+					Start: cur.Start,
+					End:   j.Start,
+				})
+			}
+			cur.Start = max(cur.Start, j.End)
+			if cur.IsEmpty() {
+				break
+			}
+		}
+		if !cur.IsEmpty() {
+			res = append(res, cur)
+		}
+	}
+	return Set{
+		ranges: res,
+	}
+}
@@ -0,0 +1,95 @@
+package interval
+
+import (
+	"math/bits"
+	"testing"
+)
+
+const setDomain = 64
+
+// bitmapRange returns the bits of the integers in r.
+func bitmapRange(r Range) uint64 {
+	var res uint64
+	for x := max(r.Start, 0); x < min(r.End, setDomain); x++ {
+		res |= 1 << x
+	}
+	return res
+}
+
+// checkSet verifies that s is canonical and holds exactly the integers of
+// model.
+func checkSet(t *testing.T, s Set, model uint64) {
+	t.Helper()
+	for n, i := range s.ranges {
+		if i.IsEmpty() {
+			t.Fatalf("Empty range %v in %v", i, s.ranges)
+		}
+		if n > 0 && s.ranges[n-1].End >= i.Start {
+			t.Fatalf("Unsorted or adjacent ranges %v", s.ranges)
+		}
+	}
+	if v := s.Len(); v != bits.OnesCount64(model) {
+		t.Fatalf("Invalid len %d for %v", v, s.ranges)
+	}
+	for x := -2; x < setDomain+2; x++ {
+		exp := x >= 0 && x < setDomain && model&(1<<x) != 0
+		if s.Contains(x) != exp {
+			t.Fatalf("Invalid contains %d for %v", x, s.ranges)
+		}
+	}
+	var rebuilt Set
+	for x := 0; x < setDomain; x++ {
+		if model&(1<<x) != 0 {
+			rebuilt.Insert(Range{Start: x, End: x + 1})
+		}
+	}
+	if !s.Equal(rebuilt) {
+		t.Fatalf("Set %v is not equal to %v", s.ranges, rebuilt.ranges)
+	}
+}
+
+// FuzzSet interprets ops as a sequence of (op, a, b) triples applied to two
+// sets and checks every result against a bitmap of the same integers.
+func FuzzSet(f *testing.F) {
+	for _, i := range [][]byte{
+		{0, 3, 10, 0, 12, 20, 1, 5, 15},
+		{0, 0, 64, 1, 10, 11, 1, 11, 12, 0, 10, 12},
+		{0, 3, 9, 8, 20, 30, 2, 0, 0, 3, 0, 0, 4, 0, 0},
+		{0, 5, 40, 8, 10, 20, 8, 25, 30, 4, 0, 0, 2, 0, 0},
+		{0, 0, 8, 0, 8, 16, 0, 20, 24, 1, 6, 22},
+		{8, 1, 63, 0, 0, 64, 3, 0, 0, 4, 0, 0, 4, 1, 1},
+	} {
+		f.Add(i)
+	}
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		var sets [2]Set
+		var models [2]uint64
+		for ; len(ops) >= 3; ops = ops[3:] {
+			op, k := ops[0]%5, ops[0]/8%2
+			r := Range{
+				Start: int(ops[1] % (setDomain + 1)),
+				End:   int(ops[2] % (setDomain + 1)),
+			}
+			o := 1 - k
+			switch op {
+			case 0:
+				sets[k].Insert(r)
+				models[k] |= bitmapRange(r)
+			case 1:
+				sets[k].Remove(r)
+				models[k] &^= bitmapRange(r)
+			case 2:
+				sets[k] = sets[k].Union(sets[o])
+				models[k] |= models[o]
+			case 3:
+				sets[k] = sets[k].Intersect(sets[o])
+				models[k] &= models[o]
+			case 4:
+				sets[k] = sets[k].Subtract(sets[o])
+				models[k] &^= models[o]
+			}
+			checkSet(t, sets[0], models[0])
+			checkSet(t, sets[1], models[1])
+		}
+	})
+}